@@ -66,7 +66,7 @@ type AddOn struct {
 }
 
 func (c *Client) GetAddOn(ctx context.Context, addOnCode string) (*AddOn, error) {
-	u := c.url("add_ons/"+addOnCode, nil)
+	u := c.url("GetAddOn", "add_ons/{code}", nil, addOnCode)
 	result, err := get[addOnResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -75,13 +75,13 @@ func (c *Client) GetAddOn(ctx context.Context, addOnCode string) (*AddOn, error)
 }
 
 func (c *Client) ListAddOns(ctx context.Context, addOnListInput *AddOnListInput) (*AddOnList, error) {
-	u := c.url("add_ons", addOnListInput.query())
+	u := c.url("ListAddOns", "add_ons", addOnListInput.query())
 
 	return get[AddOnList](ctx, c, u)
 }
 
 func (c *Client) CreateAddOn(ctx context.Context, addOnInput *AddOnInput) (*AddOn, error) {
-	u := c.url("add_ons", nil)
+	u := c.url("CreateAddOn", "add_ons", nil)
 	result, err := post[addOnParams, addOnResult](
 		ctx,
 		c,
@@ -96,7 +96,7 @@ func (c *Client) CreateAddOn(ctx context.Context, addOnInput *AddOnInput) (*AddO
 }
 
 func (c *Client) UpdateAddOn(ctx context.Context, addOnInput *AddOnInput) (*AddOn, error) {
-	u := c.url("add_ons/"+addOnInput.Code, nil)
+	u := c.url("UpdateAddOn", "add_ons/{code}", nil, addOnInput.Code)
 
 	result, err := put[addOnParams, addOnResult](
 		ctx,
@@ -112,7 +112,7 @@ func (c *Client) UpdateAddOn(ctx context.Context, addOnInput *AddOnInput) (*AddO
 }
 
 func (c *Client) DeleteAddOn(ctx context.Context, addOnCode string) (*AddOn, error) {
-	u := c.url("add_ons/"+addOnCode, nil)
+	u := c.url("DeleteAddOn", "add_ons/{code}", nil, addOnCode)
 	result, err := delete[addOnResult](ctx, c, u)
 	if err != nil {
 		return nil, err
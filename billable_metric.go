@@ -119,7 +119,7 @@ type billableMetricEvaluateExpressionResult struct {
 }
 
 func (c *Client) GetBillableMetric(ctx context.Context, billableMetricCode string) (*BillableMetric, error) {
-	u := c.url("billable_metrics/"+billableMetricCode, nil)
+	u := c.url("GetBillableMetric", "billable_metrics/{code}", nil, billableMetricCode)
 
 	result, err := get[billableMetricResult](ctx, c, u)
 	if err != nil {
@@ -130,12 +130,12 @@ func (c *Client) GetBillableMetric(ctx context.Context, billableMetricCode strin
 }
 
 func (c *Client) ListBillableMetrics(ctx context.Context, billableMetricListInput *BillableMetricListInput) (*BillableMetricList, error) {
-	u := c.url("billable_metrics", billableMetricListInput.query())
+	u := c.url("ListBillableMetrics", "billable_metrics", billableMetricListInput.query())
 	return get[BillableMetricList](ctx, c, u)
 }
 
 func (c *Client) CreateBillableMetric(ctx context.Context, billableMetricInput *BillableMetricInput) (*BillableMetric, error) {
-	u := c.url("billable_metrics", nil)
+	u := c.url("CreateBillableMetric", "billable_metrics", nil)
 
 	result, err := post[billableMetricParams, billableMetricResult](
 		ctx,
@@ -151,7 +151,7 @@ func (c *Client) CreateBillableMetric(ctx context.Context, billableMetricInput *
 }
 
 func (c *Client) UpdateBillableMetric(ctx context.Context, billableMetricInput *BillableMetricInput) (*BillableMetric, error) {
-	u := c.url("billable_metrics/"+billableMetricInput.Code, nil)
+	u := c.url("UpdateBillableMetric", "billable_metrics/{code}", nil, billableMetricInput.Code)
 
 	result, err := put[billableMetricParams, billableMetricResult](
 		ctx,
@@ -167,7 +167,7 @@ func (c *Client) UpdateBillableMetric(ctx context.Context, billableMetricInput *
 }
 
 func (c *Client) DeleteBillableMetric(ctx context.Context, billableMetricCode string) (*BillableMetric, error) {
-	u := c.url("billable_metrics/"+billableMetricCode, nil)
+	u := c.url("DeleteBillableMetric", "billable_metrics/{code}", nil, billableMetricCode)
 
 	result, err := delete[billableMetricResult](
 		ctx,
@@ -182,7 +182,7 @@ func (c *Client) DeleteBillableMetric(ctx context.Context, billableMetricCode st
 }
 
 func (c *Client) EvaluateBillableMetricExpression(ctx context.Context, evaluateExpressingInput *BillableMetricEvaluateExpressionInput) (*BillableMetricEvaluateExpressionResultValue, error) {
-	u := c.url("billable_metrics/evaluate_expression", nil)
+	u := c.url("EvaluateBillableMetricExpression", "billable_metrics/evaluate_expression", nil)
 
 	result, err := post[BillableMetricEvaluateExpressionInput, billableMetricEvaluateExpressionResult](
 		ctx,
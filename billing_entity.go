@@ -0,0 +1,142 @@
+package lago
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type billingEntityParams struct {
+	BillingEntity *BillingEntityInput `json:"billing_entity"`
+}
+
+type billingEntityResult struct {
+	BillingEntity *BillingEntity `json:"billing_entity,omitempty"`
+}
+
+type BillingEntityList struct {
+	BillingEntities []*BillingEntity `json:"billing_entities,omitempty"`
+}
+
+type BillingEntityBillingConfigurationInput struct {
+	InvoiceGracePeriod int    `json:"invoice_grace_period,omitempty"`
+	InvoiceFooter      string `json:"invoice_footer,omitempty"`
+	DocumentLocale     string `json:"document_locale,omitempty"`
+}
+
+type BillingEntityBillingConfiguration struct {
+	InvoiceGracePeriod int    `json:"invoice_grace_period,omitempty"`
+	InvoiceFooter      string `json:"invoice_footer,omitempty"`
+	DocumentLocale     string `json:"document_locale,omitempty"`
+}
+
+// BillingEntityInput creates or updates a billing entity.
+// TaxCodes replaces the taxes applied by default to the entity's customers,
+// and InvoiceCustomSectionCodes the custom sections shown on its invoices.
+// FinalizeZeroAmountInvoice is left unchanged when nil.
+type BillingEntityInput struct {
+	Code string `json:"code,omitempty"`
+	Name string `json:"name,omitempty"`
+
+	Email                     string                        `json:"email,omitempty"`
+	AddressLine1              string                        `json:"address_line1,omitempty"`
+	AddressLine2              string                        `json:"address_line2,omitempty"`
+	City                      string                        `json:"city,omitempty"`
+	Zipcode                   string                        `json:"zipcode,omitempty"`
+	State                     string                        `json:"state,omitempty"`
+	Country                   string                        `json:"country,omitempty"`
+	DefaultCurrency           Currency                      `json:"default_currency,omitempty"`
+	LegalName                 string                        `json:"legal_name,omitempty"`
+	LegalNumber               string                        `json:"legal_number,omitempty"`
+	DocumentNumbering         OrganizationDocumentNumbering `json:"document_numbering,omitempty"`
+	DocumentNumberPrefix      string                        `json:"document_number_prefix,omitempty"`
+	NetPaymentTerm            int                           `json:"net_payment_term,omitempty"`
+	TaxIdentificationNumber   string                        `json:"tax_identification_number,omitempty"`
+	Timezone                  string                        `json:"timezone,omitempty"`
+	EmailSettings             []string                      `json:"email_settings,omitempty"`
+	FinalizeZeroAmountInvoice *bool                         `json:"finalize_zero_amount_invoice,omitempty"`
+
+	BillingConfiguration BillingEntityBillingConfigurationInput `json:"billing_configuration,omitempty"`
+
+	TaxCodes                  []string `json:"tax_codes,omitempty"`
+	InvoiceCustomSectionCodes []string `json:"invoice_custom_section_codes,omitempty"`
+}
+
+type BillingEntity struct {
+	LagoID    uuid.UUID `json:"lago_id,omitempty"`
+	Code      string    `json:"code,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	IsDefault bool      `json:"is_default,omitempty"`
+
+	Email                     string                        `json:"email,omitempty"`
+	AddressLine1              string                        `json:"address_line1,omitempty"`
+	AddressLine2              string                        `json:"address_line2,omitempty"`
+	City                      string                        `json:"city,omitempty"`
+	Zipcode                   string                        `json:"zipcode,omitempty"`
+	State                     string                        `json:"state,omitempty"`
+	Country                   string                        `json:"country,omitempty"`
+	DefaultCurrency           Currency                      `json:"default_currency,omitempty"`
+	LegalName                 string                        `json:"legal_name,omitempty"`
+	LegalNumber               string                        `json:"legal_number,omitempty"`
+	DocumentNumbering         OrganizationDocumentNumbering `json:"document_numbering,omitempty"`
+	DocumentNumberPrefix      string                        `json:"document_number_prefix,omitempty"`
+	NetPaymentTerm            int                           `json:"net_payment_term,omitempty"`
+	TaxIdentificationNumber   string                        `json:"tax_identification_number,omitempty"`
+	Timezone                  string                        `json:"timezone,omitempty"`
+	EmailSettings             []string                      `json:"email_settings,omitempty"`
+	FinalizeZeroAmountInvoice bool                          `json:"finalize_zero_amount_invoice,omitempty"`
+
+	BillingConfiguration BillingEntityBillingConfiguration `json:"billing_configuration,omitempty"`
+
+	Taxes                         []*Tax                  `json:"taxes,omitempty"`
+	SelectedInvoiceCustomSections []*InvoiceCustomSection `json:"selected_invoice_custom_sections,omitempty"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+func (c *Client) GetBillingEntity(ctx context.Context, billingEntityCode string) (*BillingEntity, error) {
+	u := c.url("GetBillingEntity", "billing_entities/{code}", nil, billingEntityCode)
+	result, err := get[billingEntityResult](ctx, c, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.BillingEntity, nil
+}
+
+func (c *Client) ListBillingEntities(ctx context.Context) (*BillingEntityList, error) {
+	u := c.url("ListBillingEntities", "billing_entities", nil)
+	return get[BillingEntityList](ctx, c, u)
+}
+
+func (c *Client) CreateBillingEntity(ctx context.Context, billingEntityInput *BillingEntityInput) (*BillingEntity, error) {
+	u := c.url("CreateBillingEntity", "billing_entities", nil)
+	result, err := post[billingEntityParams, billingEntityResult](
+		ctx,
+		c,
+		u,
+		&billingEntityParams{BillingEntity: billingEntityInput},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.BillingEntity, nil
+}
+
+func (c *Client) UpdateBillingEntity(ctx context.Context, billingEntityInput *BillingEntityInput) (*BillingEntity, error) {
+	u := c.url("UpdateBillingEntity", "billing_entities/{code}", nil, billingEntityInput.Code)
+	result, err := put[billingEntityParams, billingEntityResult](
+		ctx,
+		c,
+		u,
+		&billingEntityParams{BillingEntity: billingEntityInput},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.BillingEntity, nil
+}
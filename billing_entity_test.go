@@ -0,0 +1,78 @@
+package lago
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestClient_BillingEntities(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]requestTest{
+		"create": {
+			call: func(c *Client) error {
+				_, err := c.CreateBillingEntity(ctx, &BillingEntityInput{Code: "eu", Name: "Acme EU", DefaultCurrency: EUR})
+				return err
+			},
+			response:   `{"billing_entity":{"code":"eu"}}`,
+			wantMethod: http.MethodPost,
+			wantURI:    "/api/v1/billing_entities",
+			wantBody:   `{"billing_entity":{"code":"eu","name":"Acme EU","default_currency":"EUR","billing_configuration":{}}}`,
+		},
+		"update taxes and sections": {
+			call: func(c *Client) error {
+				_, err := c.UpdateBillingEntity(ctx, &BillingEntityInput{
+					Code:                      "eu",
+					TaxCodes:                  []string{"vat_fr"},
+					InvoiceCustomSectionCodes: []string{"bank_details"},
+				})
+				return err
+			},
+			response:   `{"billing_entity":{"code":"eu"}}`,
+			wantMethod: http.MethodPut,
+			wantURI:    "/api/v1/billing_entities/eu",
+			wantBody:   `{"billing_entity":{"code":"eu","billing_configuration":{},"tax_codes":["vat_fr"],"invoice_custom_section_codes":["bank_details"]}}`,
+		},
+		"update zero amount invoices": {
+			call: func(c *Client) error {
+				finalize := false
+				_, err := c.UpdateBillingEntity(ctx, &BillingEntityInput{Code: "eu", FinalizeZeroAmountInvoice: &finalize})
+				return err
+			},
+			response:   `{"billing_entity":{"code":"eu","finalize_zero_amount_invoice":false}}`,
+			wantMethod: http.MethodPut,
+			wantURI:    "/api/v1/billing_entities/eu",
+			wantBody:   `{"billing_entity":{"code":"eu","finalize_zero_amount_invoice":false,"billing_configuration":{}}}`,
+		},
+		"get": {
+			call: func(c *Client) error {
+				_, err := c.GetBillingEntity(ctx, "eu")
+				return err
+			},
+			response:   `{"billing_entity":{"code":"eu"}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/billing_entities/eu",
+		},
+		"list": {
+			call: func(c *Client) error {
+				_, err := c.ListBillingEntities(ctx)
+				return err
+			},
+			response:   `{"billing_entities":[{"code":"eu"},{"code":"us"}]}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/billing_entities",
+		},
+		"list invoices": {
+			call: func(c *Client) error {
+				_, err := c.ListInvoice(ctx, &InvoiceListInput{BillingEntityCodes: []string{"eu", "us"}})
+				return err
+			},
+			response:   `{"invoices":[],"meta":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/invoices?billing_entity_codes%5B%5D=eu&billing_entity_codes%5B%5D=us",
+		},
+	}
+
+	runRequestTests(t, tests)
+}
@@ -0,0 +1,303 @@
+package lago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without sending the request, while the circuit
+// breaker of the API host is open.
+var ErrCircuitOpen = errors.New("lago: circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every request with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets trial requests through to probe the host.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreakerPolicy configures the circuit breakers of the client, one per
+// API host, so the API and event ingestion hosts trip separately.
+//
+// A request fails when the transport returns an error, including a timeout,
+// or when the response is a server error. The circuit opens when the ratio of
+// failed requests within a window reaches FailureRatio. After CoolDown, the
+// circuit is half-open and lets HalfOpenRequests trial requests through: it
+// closes once they all succeed, and opens again as soon as one fails.
+type CircuitBreakerPolicy struct {
+	// FailureRatio is the ratio of failed requests, between 0 and 1, opening
+	// the circuit.
+	FailureRatio float64
+	// MinRequests is the number of requests within a window before the
+	// circuit can open.
+	MinRequests int
+	// Window is the duration over which failed requests are counted.
+	Window time.Duration
+	// CoolDown is how long the circuit stays open.
+	CoolDown time.Duration
+	// HalfOpenRequests is the number of trial requests closing the circuit.
+	HalfOpenRequests int
+	// OnStateChange, if set, is called when the circuit of a host changes
+	// state. The change from open to half-open happens when the cool-down is
+	// over and the breaker is next used, by a request or State. It is called
+	// synchronously, and must not block.
+	OnStateChange func(host string, from, to CircuitState)
+}
+
+// DefaultCircuitBreakerPolicy returns a policy opening the circuit for 30
+// seconds when half of at least 10 requests fail within 30 seconds.
+func DefaultCircuitBreakerPolicy() *CircuitBreakerPolicy {
+	return &CircuitBreakerPolicy{
+		FailureRatio:     0.5,
+		MinRequests:      10,
+		Window:           30 * time.Second,
+		CoolDown:         30 * time.Second,
+		HalfOpenRequests: 1,
+	}
+}
+
+func (p *CircuitBreakerPolicy) Validate() error {
+	if p.FailureRatio <= 0 || p.FailureRatio > 1 {
+		return errors.New("FailureRatio must be greater than 0 and at most 1")
+	}
+	if p.MinRequests < 1 {
+		return errors.New("MinRequests must be at least 1")
+	}
+	if p.Window <= 0 {
+		return errors.New("Window must be positive")
+	}
+	if p.CoolDown <= 0 {
+		return errors.New("CoolDown must be positive")
+	}
+	if p.HalfOpenRequests < 1 {
+		return errors.New("HalfOpenRequests must be at least 1")
+	}
+	return nil
+}
+
+// CircuitBreaker tracks the failures of the requests sent to a host.
+// A nil breaker never opens.
+// It is safe for concurrent use by multiple goroutines.
+type CircuitBreaker struct {
+	host   string
+	policy *CircuitBreakerPolicy
+
+	mu    sync.Mutex
+	state CircuitState
+	// generation is incremented on every state change, so the outcome of a
+	// request let through in a previous state is ignored.
+	generation uint64
+	// since is when the current window started while closed, and when the
+	// circuit opened while open.
+	since    time.Time
+	requests int
+	failures int
+	// trials are the trial requests in flight, and successes the ones that
+	// succeeded, while half-open.
+	trials    int
+	successes int
+}
+
+// NewCircuitBreaker returns a closed breaker of the host.
+func NewCircuitBreaker(host string, policy *CircuitBreakerPolicy) *CircuitBreaker {
+	return &CircuitBreaker{
+		host:   host,
+		policy: policy,
+		since:  time.Now(),
+	}
+}
+
+// State returns the current state of the breaker. An open breaker whose
+// cool-down is over becomes half-open, calling OnStateChange, whether the
+// first to notice is State or a request.
+func (b *CircuitBreaker) State() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+
+	b.mu.Lock()
+	from := b.advance(time.Now())
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return to
+}
+
+// advance moves an open breaker whose cool-down is over to half-open, and
+// returns the state it was in.
+func (b *CircuitBreaker) advance(now time.Time) CircuitState {
+	from := b.state
+	if b.state == CircuitOpen && now.Sub(b.since) >= b.policy.CoolDown {
+		b.setState(CircuitHalfOpen, now)
+	}
+	return from
+}
+
+// allow reports whether a request can be sent, returning an error wrapping
+// ErrCircuitOpen when it cannot. A request allowed through must be followed
+// by a call to done, or to release when it is not sent, with the returned
+// generation.
+func (b *CircuitBreaker) allow() (uint64, error) {
+	if b == nil {
+		return 0, nil
+	}
+
+	b.mu.Lock()
+	from := b.advance(time.Now())
+
+	var err error
+	switch b.state {
+	case CircuitOpen:
+		err = fmt.Errorf("%w: %s", ErrCircuitOpen, b.host)
+	case CircuitHalfOpen:
+		if b.trials+b.successes >= b.policy.HalfOpenRequests {
+			err = fmt.Errorf("%w: %s", ErrCircuitOpen, b.host)
+		} else {
+			b.trials++
+		}
+	}
+	to, generation := b.state, b.generation
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return generation, err
+}
+
+// done records the outcome of a request allowed through in the generation.
+func (b *CircuitBreaker) done(ctx context.Context, generation uint64, res *http.Response, err error) {
+	if b == nil {
+		return
+	}
+
+	// Requests canceled by the caller say nothing about the host.
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		b.release(generation)
+		return
+	}
+	failed := err != nil || res.StatusCode >= http.StatusInternalServerError
+
+	now := time.Now()
+
+	b.mu.Lock()
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	from := b.state
+	switch b.state {
+	case CircuitClosed:
+		if now.Sub(b.since) >= b.policy.Window {
+			b.since, b.requests, b.failures = now, 0, 0
+		}
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.policy.MinRequests &&
+			float64(b.failures) >= b.policy.FailureRatio*float64(b.requests) {
+			b.setState(CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		b.trials--
+		if failed {
+			b.setState(CircuitOpen, now)
+		} else {
+			b.successes++
+			if b.successes >= b.policy.HalfOpenRequests {
+				b.setState(CircuitClosed, now)
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// release gives back a request allowed through in the generation but not
+// sent.
+func (b *CircuitBreaker) release(generation uint64) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation == b.generation && b.state == CircuitHalfOpen {
+		b.trials--
+	}
+}
+
+// setState moves the breaker to the state, resetting its counters.
+func (b *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	b.state = state
+	b.generation++
+	b.since = now
+	b.requests, b.failures = 0, 0
+	b.trials, b.successes = 0, 0
+}
+
+func (b *CircuitBreaker) notify(from, to CircuitState) {
+	if from == to || b.policy.OnStateChange == nil {
+		return
+	}
+	b.policy.OnStateChange(b.host, from, to)
+}
+
+// circuitBreaker returns the breaker of the request's host.
+func (c *Client) circuitBreaker(req *http.Request) *CircuitBreaker {
+	return c.breakers[req.URL.Host]
+}
+
+// ManagementCircuitBreaker returns the breaker of the API host, or nil when
+// Config.CircuitBreaker is not set.
+func (c *Client) ManagementCircuitBreaker() *CircuitBreaker {
+	return c.breakers[c.baseURL.Host]
+}
+
+// IngestCircuitBreaker returns the breaker of the event ingestion host, or
+// nil when Config.CircuitBreaker is not set. It is the breaker of the API
+// host when both hosts are the same.
+func (c *Client) IngestCircuitBreaker() *CircuitBreaker {
+	return c.breakers[c.ingestURL.Host]
+}
+
+func newCircuitBreakers(policy *CircuitBreakerPolicy, urls ...*url.URL) map[string]*CircuitBreaker {
+	if policy == nil {
+		return nil
+	}
+
+	breakers := make(map[string]*CircuitBreaker, len(urls))
+	for _, u := range urls {
+		if _, ok := breakers[u.Host]; !ok {
+			breakers[u.Host] = NewCircuitBreaker(u.Host, policy)
+		}
+	}
+	return breakers
+}
@@ -0,0 +1,242 @@
+package lago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testCircuitBreakerPolicy(changes *[]string) *CircuitBreakerPolicy {
+	return &CircuitBreakerPolicy{
+		FailureRatio:     0.5,
+		MinRequests:      2,
+		Window:           time.Minute,
+		CoolDown:         20 * time.Millisecond,
+		HalfOpenRequests: 1,
+		OnStateChange: func(host string, from, to CircuitState) {
+			*changes = append(*changes, fmt.Sprintf("%s %s->%s", host, from, to))
+		},
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	tests := map[string]struct {
+		trialStatus int
+		wantState   CircuitState
+		wantChanges []string
+	}{
+		"recovered": {
+			trialStatus: http.StatusOK,
+			wantState:   CircuitClosed,
+			wantChanges: []string{
+				"example.com closed->open",
+				"example.com open->half-open",
+				"example.com half-open->closed",
+			},
+		},
+		"still failing": {
+			trialStatus: http.StatusServiceUnavailable,
+			wantState:   CircuitOpen,
+			wantChanges: []string{
+				"example.com closed->open",
+				"example.com open->half-open",
+				"example.com half-open->open",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var status atomic.Int64
+			status.Store(http.StatusServiceUnavailable)
+			var sent atomic.Int64
+			client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				sent.Add(1)
+				return newTestResponse(int(status.Load()), `{"customer":{"external_id":"cus"}}`), nil
+			})
+
+			var changes []string
+			c, err := NewWithOptions("test",
+				WithBaseURL("https://example.com"),
+				WithHTTPClient(client),
+				WithCircuitBreaker(testCircuitBreakerPolicy(&changes)),
+			)
+			if err != nil {
+				t.Fatalf("NewWithOptions() = %v", err)
+			}
+
+			for range 2 {
+				if _, err := c.GetCustomer(context.Background(), "cus"); !errors.Is(err, ErrServer) {
+					t.Fatalf("GetCustomer() = %v, want %v", err, ErrServer)
+				}
+			}
+			if state := c.ManagementCircuitBreaker().State(); state != CircuitOpen {
+				t.Fatalf("State() = %s, want open", state)
+			}
+
+			if _, err := c.GetCustomer(context.Background(), "cus"); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("GetCustomer() = %v, want %v", err, ErrCircuitOpen)
+			}
+			if n := sent.Load(); n != 2 {
+				t.Errorf("sent %d requests, want 2", n)
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			if state := c.ManagementCircuitBreaker().State(); state != CircuitHalfOpen {
+				t.Fatalf("State() = %s, want half-open", state)
+			}
+
+			status.Store(int64(tt.trialStatus))
+			c.GetCustomer(context.Background(), "cus")
+
+			if state := c.ManagementCircuitBreaker().State(); state != tt.wantState {
+				t.Errorf("State() = %s, want %s", state, tt.wantState)
+			}
+			if !slices.Equal(changes, tt.wantChanges) {
+				t.Errorf("state changes = %q, want %q", changes, tt.wantChanges)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_PerHost(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "ingest.example.com" {
+			return newTestResponse(http.StatusOK, `{"event":{"transaction_id":"tx"}}`), nil
+		}
+		return nil, errors.New("connection refused")
+	})
+
+	var changes []string
+	c, err := NewWithOptions("test",
+		WithBaseURL("https://example.com"),
+		WithIngestURL("https://ingest.example.com"),
+		WithHTTPClient(client),
+		WithCircuitBreaker(testCircuitBreakerPolicy(&changes)),
+	)
+	if err != nil {
+		t.Fatalf("NewWithOptions() = %v", err)
+	}
+
+	for range 3 {
+		c.GetCustomer(context.Background(), "cus")
+	}
+	if state := c.ManagementCircuitBreaker().State(); state != CircuitOpen {
+		t.Errorf("management State() = %s, want open", state)
+	}
+
+	if _, err := c.CreateEvent(context.Background(), &EventInput{TransactionID: "tx"}); err != nil {
+		t.Errorf("CreateEvent() = %v", err)
+	}
+	if state := c.IngestCircuitBreaker().State(); state != CircuitClosed {
+		t.Errorf("ingest State() = %s, want closed", state)
+	}
+}
+
+func TestCircuitBreaker_Canceled(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, req.Context().Err()
+	})
+
+	var changes []string
+	c := newTestClient(t, client, nil)
+	c.breakers = newCircuitBreakers(testCircuitBreakerPolicy(&changes), c.baseURL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 3 {
+		if _, err := c.GetCustomer(ctx, "cus"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("GetCustomer() = %v, want %v", err, context.Canceled)
+		}
+	}
+
+	if state := c.ManagementCircuitBreaker().State(); state != CircuitClosed {
+		t.Errorf("State() = %s, want closed", state)
+	}
+}
+
+func TestCircuitBreakerPolicy_Validate(t *testing.T) {
+	tests := map[string]struct {
+		modify  func(p *CircuitBreakerPolicy)
+		wantErr bool
+	}{
+		"default": {
+			modify: func(p *CircuitBreakerPolicy) {},
+		},
+		"zero failure ratio": {
+			modify:  func(p *CircuitBreakerPolicy) { p.FailureRatio = 0 },
+			wantErr: true,
+		},
+		"failure ratio above 1": {
+			modify:  func(p *CircuitBreakerPolicy) { p.FailureRatio = 1.5 },
+			wantErr: true,
+		},
+		"no min requests": {
+			modify:  func(p *CircuitBreakerPolicy) { p.MinRequests = 0 },
+			wantErr: true,
+		},
+		"no window": {
+			modify:  func(p *CircuitBreakerPolicy) { p.Window = 0 },
+			wantErr: true,
+		},
+		"no cool down": {
+			modify:  func(p *CircuitBreakerPolicy) { p.CoolDown = 0 },
+			wantErr: true,
+		},
+		"no half-open requests": {
+			modify:  func(p *CircuitBreakerPolicy) { p.HalfOpenRequests = 0 },
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := DefaultCircuitBreakerPolicy()
+			tt.modify(p)
+			if err := p.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_StaleOutcome(t *testing.T) {
+	var changes []string
+	policy := testCircuitBreakerPolicy(&changes)
+	policy.MinRequests = 1
+	b := NewCircuitBreaker("example.com", policy)
+	ctx := context.Background()
+
+	slow, err := b.allow()
+	if err != nil {
+		t.Fatalf("allow() = %v", err)
+	}
+	failing, err := b.allow()
+	if err != nil {
+		t.Fatalf("allow() = %v", err)
+	}
+	b.done(ctx, failing, nil, errors.New("connection refused"))
+
+	time.Sleep(policy.CoolDown)
+	if state := b.State(); state != CircuitHalfOpen {
+		t.Fatalf("State() = %s, want half-open", state)
+	}
+	want := []string{"example.com closed->open", "example.com open->half-open"}
+	if !slices.Equal(changes, want) {
+		t.Errorf("state changes = %q, want %q", changes, want)
+	}
+
+	// The request let through while closed does not count as a trial.
+	b.done(ctx, slow, newTestResponse(http.StatusOK, ""), nil)
+	if state := b.State(); state != CircuitHalfOpen {
+		t.Errorf("State() after a stale success = %s, want half-open", state)
+	}
+	if _, err := b.allow(); err != nil {
+		t.Errorf("allow() trial = %v", err)
+	}
+}
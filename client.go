@@ -2,10 +2,17 @@ package lago
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"crypto/rsa"
 	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
+	"time"
 )
 
 const (
@@ -20,17 +27,32 @@ type HTTPClient interface {
 }
 
 // Client is a struct that holds the configuration for the client.
-// Zero values are not valid. Use New to create a new client.
+// Zero values are not valid. Use New to create a client from a Config
+// specifying every field, or NewWithOptions to apply defaults.
 // The client is safe for concurrent use by multiple goroutines.
-//
-// TODO(nikola-jokic): Should defaults be applied and functional options used?
-// For now, I think it might be better to force specifying all the fields.
 type Client struct {
 	baseURL    *url.URL
+	ingestURL  *url.URL
 	debug      bool
 	userAgent  string
 	bearerAuth string
+	headers    http.Header
 	client     HTTPClient
+	timeout    time.Duration
+	retry      *RetryPolicy
+
+	interceptors []Interceptor
+
+	managementLimiter *RateLimiter
+	ingestLimiter     *RateLimiter
+	breakers          map[string]*CircuitBreaker
+
+	logger         *slog.Logger
+	redactedFields map[string]bool
+
+	idempotencyKeys bool
+	webhookSecret   string
+	webhookKeys     *webhookKeyCache
 }
 
 func New(cfg Config) (*Client, error) {
@@ -40,110 +62,98 @@ func New(cfg Config) (*Client, error) {
 
 	baseURL, _ := url.Parse(cfg.BaseURL)
 
+	ingestURL := baseURL
+	if cfg.IngestURL != "" {
+		ingestURL, _ = url.Parse(cfg.IngestURL)
+	}
+
+	userAgent := "lago-go github.com/nikola-jokic/lago-go"
+	if cfg.UserAgentSuffix != "" {
+		userAgent += " " + cfg.UserAgentSuffix
+	}
+
+	var webhookPublicKey *rsa.PublicKey
+	if cfg.WebhookPublicKey != "" {
+		// Already validated, so the key is known to parse.
+		webhookPublicKey, _ = parseWebhookPublicKey([]byte(cfg.WebhookPublicKey))
+	}
+
+	var managementLimiter, ingestLimiter *RateLimiter
+	if cfg.RateLimit != nil {
+		managementLimiter = NewRateLimiter(cfg.RateLimit.Management)
+		ingestLimiter = NewRateLimiter(cfg.RateLimit.Ingest)
+	}
+
 	return &Client{
 		baseURL:    baseURL,
+		ingestURL:  ingestURL,
 		debug:      cfg.Debug,
-		userAgent:  "lago-go github.com/nikola-jokic/lago-go",
+		userAgent:  userAgent,
 		bearerAuth: "Bearer " + cfg.APIKey,
+		headers:    cfg.Headers.Clone(),
 		client:     cfg.Client,
-	}, nil
-}
+		timeout:    cfg.Timeout,
+		retry:      cfg.Retry,
 
-func get[R any](ctx context.Context, client *Client, path string) (*R, error) {
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodGet,
-		path,
-		nil,
-	)
-	if err != nil {
-		return nil, err
-	}
+		interceptors: slices.Clone(cfg.Interceptors),
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", client.userAgent)
-	req.Header.Set("Authorization", client.bearerAuth)
+		managementLimiter: managementLimiter,
+		ingestLimiter:     ingestLimiter,
+		breakers:          newCircuitBreakers(cfg.CircuitBreaker, baseURL, ingestURL),
 
-	res, err := client.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		return nil, readError(res.Body)
-	}
-
-	var result R
-	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, err
-	}
+		logger:         newDebugLogger(&cfg),
+		redactedFields: newRedactedFields(cfg.RedactedFields),
 
-	return &result, nil
+		idempotencyKeys: cfg.IdempotencyKeys,
+		webhookSecret:   cfg.WebhookSecret,
+		webhookKeys:     newWebhookKeyCache(cfg.WebhookPublicKeyTTL, webhookPublicKey),
+	}, nil
 }
 
-func delete[R any](ctx context.Context, client *Client, path string) (*R, error) {
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodDelete,
-		path,
-		nil,
-	)
-	if err != nil {
-		return nil, err
-	}
+func get[R any](ctx context.Context, client *Client, e *endpoint) (*R, error) {
+	return do[R](ctx, client, http.MethodGet, e, nil)
+}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", client.userAgent)
-	req.Header.Set("Authorization", client.bearerAuth)
+func delete[R any](ctx context.Context, client *Client, e *endpoint) (*R, error) {
+	return do[R](ctx, client, http.MethodDelete, e, nil)
+}
 
-	res, err := client.client.Do(req)
-	if err != nil {
+func post[B, R any](ctx context.Context, client *Client, e *endpoint, body *B) (*R, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		return nil, readError(res.Body)
-	}
 
-	var result R
-	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, err
-	}
+	return do[R](ctx, client, http.MethodPost, e, buf.Bytes())
+}
 
-	return &result, nil
+func postWithoutBody[R any](ctx context.Context, client *Client, e *endpoint) (*R, error) {
+	return do[R](ctx, client, http.MethodPost, e, nil)
 }
 
-func post[B, R any](ctx context.Context, client *Client, path string, body *B) (*R, error) {
+func put[B, R any](ctx context.Context, client *Client, e *endpoint, body *B) (*R, error) {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(body); err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		path,
-		&buf,
-	)
-	if err != nil {
-		return nil, err
-	}
+	return do[R](ctx, client, http.MethodPut, e, buf.Bytes())
+}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", client.userAgent)
-	req.Header.Set("Authorization", client.bearerAuth)
+func putWithoutBody[R any](ctx context.Context, client *Client, e *endpoint) (*R, error) {
+	return do[R](ctx, client, http.MethodPut, e, nil)
+}
 
-	res, err := client.client.Do(req)
+// do sends the request and decodes the successful response into R.
+func do[R any](ctx context.Context, client *Client, method string, e *endpoint, body []byte) (*R, error) {
+	res, err := client.do(ctx, method, e, body)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, readError(res.Body)
+		return nil, readError(res)
 	}
 
 	var result R
@@ -154,115 +164,162 @@ func post[B, R any](ctx context.Context, client *Client, path string, body *B) (
 	return &result, nil
 }
 
-func postWithoutBody[R any](ctx context.Context, client *Client, path string) (*R, error) {
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		path,
-		nil,
-	)
-	if err != nil {
-		return nil, err
+// do sends the request through the interceptors of the client.
+// The caller is responsible for closing the response body.
+func (c *Client) do(ctx context.Context, method string, e *endpoint, body []byte) (*http.Response, error) {
+	op := e.op
+	op.Method = method
+
+	opts := requestOptionsFromContext(ctx)
+	if timeout := cmp.Or(opts.timeout, c.timeout); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+
+		res, err := c.doRequest(ctx, &op, e.url, body, &opts)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+		return res, nil
 	}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", client.userAgent)
-	req.Header.Set("Authorization", client.bearerAuth)
+	return c.doRequest(ctx, &op, e.url, body, &opts)
+}
 
-	res, err := client.client.Do(req)
+func (c *Client) doRequest(ctx context.Context, op *Operation, path string, body []byte, opts *requestOptions) (*http.Response, error) {
+	ctx = context.WithValue(ctx, operationContextKey{}, op)
+	req, err := c.newRequest(ctx, op.Method, path, body, opts.header)
 	if err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return nil, readError(res.Body)
+	// invoke records the metadata of the requests it sends. An interceptor
+	// answering without calling it leaves Attempts at zero, so the response
+	// it returned is recorded here.
+	start := time.Now()
+	if opts.metadata != nil {
+		*opts.metadata = ResponseMetadata{}
 	}
-
-	var result R
-	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, err
+	res, err := c.intercept(op, req)
+	if opts.metadata != nil && opts.metadata.Attempts == 0 {
+		opts.metadata.record(res, 0, time.Since(start))
 	}
-
-	return &result, nil
+	if err == nil && res == nil {
+		return nil, errors.New("lago: interceptor returned neither a response nor an error")
+	}
+	return res, err
 }
 
-func put[B, R any](ctx context.Context, client *Client, path string, body *B) (*R, error) {
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(body); err != nil {
-		return nil, err
+// invoke sends the request, retrying it according to the client's retry
+// policy. It ends the chain of interceptors.
+func (c *Client) invoke(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	opts := requestOptionsFromContext(ctx)
+	limiter := c.rateLimiter(req)
+	breaker := c.circuitBreaker(req)
+
+	start := time.Now()
+	// finish records the metadata of the last response, if any, and of the
+	// requests sent, whichever way the call ends.
+	var last *http.Response
+	finish := func(sent int) {
+		if opts.metadata != nil {
+			opts.metadata.record(last, sent, time.Since(start))
+		}
 	}
 
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPut,
-		path,
-		&buf,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", client.userAgent)
-	req.Header.Set("Authorization", client.bearerAuth)
-
-	res, err := client.client.Do(req)
-	if err != nil {
-		return nil, err
+	for attempt := 1; ; attempt++ {
+		if req.GetBody != nil {
+			// A previous attempt, or call, may have consumed the body, so
+			// rewind it.
+			var err error
+			req.Body, err = req.GetBody()
+			if err != nil {
+				finish(attempt - 1)
+				return nil, err
+			}
+		}
+
+		generation, err := breaker.allow()
+		if err != nil {
+			finish(attempt - 1)
+			return nil, err
+		}
+		if err := limiter.take(ctx); err != nil {
+			breaker.release(generation)
+			finish(attempt - 1)
+			return nil, err
+		}
+
+		res, err := c.send(req, attempt)
+		limiter.observe(res)
+		breaker.done(ctx, generation, res, err)
+		last = res
+
+		delay, ok := c.retry.next(ctx, attempt, res, err)
+		if !ok {
+			finish(attempt)
+			return res, err
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			finish(attempt)
+			return nil, err
+		}
 	}
-	defer res.Body.Close()
+}
 
-	if res.StatusCode != http.StatusOK {
-		return nil, readError(res.Body)
+func (c *Client) newRequest(ctx context.Context, method, path string, body []byte, header http.Header) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
 	}
 
-	var result R
-	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
-}
-
-func putWithoutBody[R any](ctx context.Context, client *Client, path string) (*R, error) {
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPut,
-		path,
-		nil,
-	)
+	req, err := http.NewRequestWithContext(ctx, method, path, r)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, h := range []http.Header{c.headers, header} {
+		for k, values := range h {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+	}
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", client.userAgent)
-	req.Header.Set("Authorization", client.bearerAuth)
-
-	res, err := client.client.Do(req)
-	if err != nil {
-		return nil, err
+	if method == http.MethodPost || method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	defer res.Body.Close()
-
-	if res.StatusCode != http.StatusOK {
-		return nil, readError(res.Body)
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Authorization", c.bearerAuth)
+	if key := c.idempotencyKey(ctx, method); key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
 	}
 
-	var result R
-	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, err
-	}
+	return req, nil
+}
 
-	return &result, nil
+// url returns the endpoint of the named operation. The placeholders of the
+// path template, such as "customers/{external_customer_id}", are replaced by
+// the params, in order.
+func (c *Client) url(name, template string, q url.Values, params ...string) *endpoint {
+	return newEndpoint(c.baseURL, name, template, q, params)
+}
+
+// ingest returns the endpoint of an event ingestion operation.
+func (c *Client) ingest(name, template string, q url.Values, params ...string) *endpoint {
+	return newEndpoint(c.ingestURL, name, template, q, params)
 }
 
-func (c *Client) url(path string, q url.Values) string {
-	u := *c.baseURL
+func apiURL(base *url.URL, path string, q url.Values) string {
+	u := *base
 	u.Path = ApiV1Path + path
 	u.RawQuery = q.Encode()
 	return u.String()
@@ -0,0 +1,469 @@
+package lago
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type httpClientFunc func(req *http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestClient(t *testing.T, client HTTPClient, retry *RetryPolicy) *Client {
+	t.Helper()
+
+	c, err := New(Config{
+		BaseURL: "https://example.com",
+		APIKey:  "test",
+		Client:  client,
+		Retry:   retry,
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	return c
+}
+
+func newTestResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// requestTest is a call of the client checked against the request it sends.
+type requestTest struct {
+	call       func(c *Client) error
+	response   string
+	wantMethod string
+	wantURI    string
+	wantBody   string
+}
+
+// runRequestTests runs each call against a client answering with its
+// response, and checks the method, URI and JSON body of the request.
+func runRequestTests(t *testing.T, tests map[string]requestTest) {
+	t.Helper()
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotMethod, gotURI, gotBody string
+			client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				gotMethod, gotURI = req.Method, req.URL.RequestURI()
+				if req.Body != nil {
+					b, _ := io.ReadAll(req.Body)
+					gotBody = string(b)
+				}
+				return newTestResponse(http.StatusOK, tt.response), nil
+			})
+
+			if err := tt.call(newTestClient(t, client, nil)); err != nil {
+				t.Fatalf("call = %v", err)
+			}
+
+			if gotMethod != tt.wantMethod || gotURI != tt.wantURI {
+				t.Errorf("request = %s %s, want %s %s", gotMethod, gotURI, tt.wantMethod, tt.wantURI)
+			}
+			if tt.wantBody != "" && gotBody != tt.wantBody+"\n" {
+				t.Errorf("body = %s, want %s", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+func testRetryPolicy() *RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 10 * time.Millisecond
+	return p
+}
+
+func TestClient_RetryResendsBody(t *testing.T) {
+	var bodies []string
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+
+		switch len(bodies) {
+		case 1:
+			return nil, errors.New("connection reset")
+		case 2:
+			return newTestResponse(http.StatusServiceUnavailable, `{"status":503}`), nil
+		default:
+			return newTestResponse(http.StatusOK, `{"event":{"transaction_id":"tx"}}`), nil
+		}
+	})
+
+	c := newTestClient(t, client, testRetryPolicy())
+
+	event, err := c.CreateEvent(context.Background(), &EventInput{TransactionID: "tx"})
+	if err != nil {
+		t.Fatalf("CreateEvent() = %v", err)
+	}
+	if event.TransactionID != "tx" {
+		t.Errorf("TransactionID = %q, want %q", event.TransactionID, "tx")
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(bodies))
+	}
+	for i, b := range bodies {
+		if b != bodies[0] || b == "" {
+			t.Errorf("attempt %d body = %q, want %q", i+1, b, bodies[0])
+		}
+	}
+}
+
+func TestClient_RetryStopsAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return newTestResponse(http.StatusTooManyRequests, `{"status":429,"error":"Too Many Requests"}`), nil
+	})
+
+	policy := testRetryPolicy()
+	c := newTestClient(t, client, policy)
+
+	if _, err := c.GetCustomer(context.Background(), "customer"); err == nil {
+		t.Fatal("GetCustomer() error = nil, want error")
+	}
+	if attempts != policy.MaxAttempts {
+		t.Errorf("attempts = %d, want %d", attempts, policy.MaxAttempts)
+	}
+}
+
+func TestClient_RetryNotRetryableStatus(t *testing.T) {
+	attempts := 0
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return newTestResponse(http.StatusUnprocessableEntity, `{"status":422}`), nil
+	})
+
+	c := newTestClient(t, client, testRetryPolicy())
+
+	if _, err := c.GetCustomer(context.Background(), "customer"); err == nil {
+		t.Fatal("GetCustomer() error = nil, want error")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestClient_RetryRespectsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	attempts := 0
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		cancel()
+		res := newTestResponse(http.StatusServiceUnavailable, `{"status":503}`)
+		res.Header.Set("Retry-After", "1")
+		return res, nil
+	})
+
+	policy := testRetryPolicy()
+	policy.MaxDelay = time.Minute
+	c := newTestClient(t, client, policy)
+
+	if _, err := c.GetCustomer(ctx, "customer"); err == nil {
+		t.Fatal("GetCustomer() error = nil, want error")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tt := map[string]struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		"empty": {
+			value:  "",
+			wantOK: false,
+		},
+		"seconds": {
+			value:  "3",
+			want:   3 * time.Second,
+			wantOK: true,
+		},
+		"http date": {
+			value:  now.Add(5 * time.Second).Format(http.TimeFormat),
+			want:   5 * time.Second,
+			wantOK: true,
+		},
+		"date in the past": {
+			value:  now.Add(-time.Minute).Format(http.TimeFormat),
+			want:   0,
+			wantOK: true,
+		},
+		"invalid": {
+			value:  "soon",
+			wantOK: false,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			h := make(http.Header)
+			if tc.value != "" {
+				h.Set("Retry-After", tc.value)
+			}
+
+			got, ok := retryAfter(h, now)
+			if ok != tc.wantOK || got != tc.want {
+				t.Errorf("retryAfter() = %v, %v, want %v, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestClient_IdempotencyKeyReusedAcrossRetries(t *testing.T) {
+	var keys []string
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		keys = append(keys, req.Header.Get(IdempotencyKeyHeader))
+		if len(keys) == 1 {
+			return newTestResponse(http.StatusBadGateway, `{"status":502}`), nil
+		}
+		return newTestResponse(http.StatusOK, `{"invoice":{}}`), nil
+	})
+
+	tt := map[string]struct {
+		ctx      context.Context
+		generate bool
+		want     string
+	}{
+		"from context": {
+			ctx:  WithIdempotencyKey(context.Background(), "invoice-1"),
+			want: "invoice-1",
+		},
+		"generated": {
+			ctx:      context.Background(),
+			generate: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			keys = nil
+
+			c := newTestClient(t, client, testRetryPolicy())
+			c.idempotencyKeys = tc.generate
+
+			if _, err := c.CreateInvoice(tc.ctx, &InvoiceOneOffInput{}); err != nil {
+				t.Fatalf("CreateInvoice() = %v", err)
+			}
+
+			if len(keys) != 2 {
+				t.Fatalf("attempts = %d, want 2", len(keys))
+			}
+			if keys[0] == "" || keys[0] != keys[1] {
+				t.Errorf("keys = %q, want the same non-empty key", keys)
+			}
+			if tc.want != "" && keys[0] != tc.want {
+				t.Errorf("key = %q, want %q", keys[0], tc.want)
+			}
+		})
+	}
+}
+
+func TestClient_IdempotencyKeyOnlyOnPost(t *testing.T) {
+	var key string
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		key = req.Header.Get(IdempotencyKeyHeader)
+		return newTestResponse(http.StatusOK, `{"customer":{}}`), nil
+	})
+
+	c := newTestClient(t, client, nil)
+	c.idempotencyKeys = true
+
+	ctx := WithIdempotencyKey(context.Background(), "customer-1")
+	if _, err := c.GetCustomer(ctx, "customer"); err != nil {
+		t.Fatalf("GetCustomer() = %v", err)
+	}
+	if key != "" {
+		t.Errorf("key = %q, want empty", key)
+	}
+}
+
+func TestClient_IngestURL(t *testing.T) {
+	var hosts []string
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		return newTestResponse(http.StatusOK, `{"event":{},"events":[]}`), nil
+	})
+
+	c, err := New(Config{
+		BaseURL:   "https://api.example.com",
+		IngestURL: "https://ingest.example.com",
+		APIKey:    "test",
+		Client:    client,
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := c.CreateEvent(ctx, &EventInput{}); err != nil {
+		t.Fatalf("CreateEvent() = %v", err)
+	}
+	if _, err := c.BatchEvents(ctx, &[]*EventInput{{}}); err != nil {
+		t.Fatalf("BatchEvents() = %v", err)
+	}
+	if _, err := c.GetEvent(ctx, "tx"); err != nil {
+		t.Fatalf("GetEvent() = %v", err)
+	}
+
+	want := []string{"ingest.example.com", "ingest.example.com", "api.example.com"}
+	if !slices.Equal(hosts, want) {
+		t.Errorf("hosts = %v, want %v", hosts, want)
+	}
+}
+
+func TestClient_DebugLogging(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		return newTestResponse(http.StatusOK, `{"customer":{"external_id":"cus","email":"jane@example.com","shipping_address":{"zipcode":"11000"}}}`), nil
+	})
+
+	var buf bytes.Buffer
+	c, err := New(Config{
+		BaseURL: "https://example.com",
+		APIKey:  "secret-api-key",
+		Client:  client,
+		Debug:   true,
+		Logger:  slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	ctx := WithIdempotencyKey(context.Background(), "customer-1")
+	customer, err := c.CreateCustomer(ctx, &CustomerInput{ExternalID: "cus", Email: "jane@example.com"})
+	if err != nil {
+		t.Fatalf("CreateCustomer() = %v", err)
+	}
+	if customer.Email != "jane@example.com" {
+		t.Errorf("Email = %q, want the response body to be readable after logging", customer.Email)
+	}
+
+	out := buf.String()
+	for _, leaked := range []string{"secret-api-key", "jane@example.com", "11000"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("log contains %q: %s", leaked, out)
+		}
+	}
+	for _, want := range []string{`"status":200`, `"method":"POST"`, "customer-1", `\"external_id\":\"cus\"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log does not contain %q: %s", want, out)
+		}
+	}
+}
+
+func TestClient_DebugLoggingLargeBody(t *testing.T) {
+	var customers []string
+	for i := range 1000 {
+		customers = append(customers, fmt.Sprintf(`{"external_id":"cus_%d","name":"Customer %d","email":"cus_%d@example.com"}`, i, i, i))
+	}
+	body := `{"customers":[` + strings.Join(customers, ",") + `],"meta":{"total_count":9007199254740993}}`
+	if len(body) <= maxLoggedBodySize {
+		t.Fatalf("body is %d bytes, want more than %d", len(body), maxLoggedBodySize)
+	}
+
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		return newTestResponse(http.StatusOK, body), nil
+	})
+
+	var buf bytes.Buffer
+	c, err := New(Config{
+		BaseURL: "https://example.com",
+		APIKey:  "test",
+		Client:  client,
+		Debug:   true,
+		Logger:  slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	if _, err := c.ListCustomers(context.Background(), &CustomerListInput{}); err != nil {
+		t.Fatalf("ListCustomers() = %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "@example.com") {
+		t.Errorf("log contains an email: %.200s", out)
+	}
+	if !strings.Contains(out, "...(truncated)") {
+		t.Errorf("log does not contain a truncated body: %.200s", out)
+	}
+}
+
+func TestClient_RedactBody(t *testing.T) {
+	c := &Client{redactedFields: newRedactedFields(nil)}
+
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"redacted": {
+			body: `{"customer":{"email":"jane@example.com","name":"Jane"}}`,
+			want: `{"customer":{"email":"[REDACTED]","name":"Jane"}}`,
+		},
+		"large number": {
+			body: `{"lago_id":9007199254740993,"amount":1.50}`,
+			want: `{"amount":1.50,"lago_id":9007199254740993}`,
+		},
+		"not json": {
+			body: `<html>Bad Gateway</html>`,
+			want: `<html>Bad Gateway</html>`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := c.redactBody([]byte(tt.body)); got != tt.want {
+				t.Errorf("redactBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_DebugLoggingDisabled(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		return newTestResponse(http.StatusOK, `{"customer":{}}`), nil
+	})
+
+	var buf bytes.Buffer
+	c, err := New(Config{
+		BaseURL: "https://example.com",
+		APIKey:  "test",
+		Client:  client,
+		Logger:  slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	if _, err := c.GetCustomer(context.Background(), "cus"); err != nil {
+		t.Fatalf("GetCustomer() = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("log = %s, want nothing", buf.String())
+	}
+}
@@ -3,7 +3,10 @@ package lago
 import (
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"net/url"
+	"time"
 )
 
 // Config is a struct that holds the configuration for the client.
@@ -12,18 +15,102 @@ type Config struct {
 	APIKey  string
 	Debug   bool
 	Client  HTTPClient
+
+	// Logger receives a debug record for every request when Debug is set.
+	// It defaults to a text logger writing to stderr.
+	Logger *slog.Logger
+	// RedactedFields are the JSON fields whose values are redacted from the
+	// logged bodies. It defaults to DefaultRedactedFields.
+	RedactedFields []string
+
+	// UserAgentSuffix is appended to the User-Agent header.
+	UserAgentSuffix string
+
+	// Headers are sent with every request. They cannot override the headers
+	// set by the client, such as Authorization.
+	Headers http.Header
+
+	// IngestURL is the base URL events are sent to, such as
+	// DefaultBaseIngestURL. Events are sent to BaseURL when it is empty.
+	IngestURL string
+
+	// Timeout bounds every call, including its retries and the reading of
+	// the response, unless WithRequestTimeout overrides it. Calls are only
+	// bounded by their context when it is zero.
+	Timeout time.Duration
+
+	// Retry configures retries of failed requests. Requests are not retried
+	// when it is nil.
+	Retry *RetryPolicy
+
+	// RateLimit enables client-side rate limiting of the requests.
+	// Requests are not limited when it is nil.
+	RateLimit *RateLimitPolicy
+
+	// CircuitBreaker enables a circuit breaker per API host, failing the
+	// requests fast while the host is failing. Requests are always sent when
+	// it is nil.
+	CircuitBreaker *CircuitBreakerPolicy
+
+	// Interceptors wrap every API call, the first being the outermost.
+	Interceptors []Interceptor
+
+	// IdempotencyKeys makes the client generate an idempotency key for every
+	// POST request whose context does not carry one. See WithIdempotencyKey.
+	IdempotencyKeys bool
+
+	// WebhookSecret is the organization's HMAC key, used to verify webhooks
+	// sent to endpoints created with the HMac signature algorithm.
+	WebhookSecret string
+
+	// WebhookPublicKey is the PEM encoded public key used to verify JWT
+	// signed webhooks. When it is set, the key is never fetched from the API,
+	// which allows offline verification.
+	WebhookPublicKey string
+
+	// WebhookPublicKeyTTL is how long the fetched webhook public key is
+	// cached. It defaults to DefaultWebhookPublicKeyTTL.
+	WebhookPublicKeyTTL time.Duration
 }
 
 func (c *Config) Validate() error {
 	if err := validateBaseURL(c.BaseURL); err != nil {
 		return fmt.Errorf("BaseURL validation error: %v", err)
 	}
+	if c.IngestURL != "" {
+		if err := validateBaseURL(c.IngestURL); err != nil {
+			return fmt.Errorf("IngestURL validation error: %v", err)
+		}
+	}
 	if c.Client == nil {
 		return errors.New("Client is nil")
 	}
 	if c.APIKey == "" {
 		return errors.New("APIKey is empty")
 	}
+	if c.Timeout < 0 {
+		return errors.New("Timeout is negative")
+	}
+	if c.WebhookPublicKey != "" {
+		if _, err := parseWebhookPublicKey([]byte(c.WebhookPublicKey)); err != nil {
+			return fmt.Errorf("WebhookPublicKey validation error: %v", err)
+		}
+	}
+	if c.Retry != nil {
+		if err := c.Retry.Validate(); err != nil {
+			return fmt.Errorf("Retry validation error: %v", err)
+		}
+	}
+	if c.RateLimit != nil {
+		if err := c.RateLimit.Validate(); err != nil {
+			return fmt.Errorf("RateLimit validation error: %v", err)
+		}
+	}
+	if c.CircuitBreaker != nil {
+		if err := c.CircuitBreaker.Validate(); err != nil {
+			return fmt.Errorf("CircuitBreaker validation error: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -3,6 +3,7 @@ package lago
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -44,6 +45,24 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		"valid IngestURL": {
+			c: &Config{
+				BaseURL:   "https://example.com",
+				IngestURL: DefaultBaseIngestURL,
+				APIKey:    uuid.NewString(),
+				Client:    &http.Client{},
+			},
+			wantErr: false,
+		},
+		"invalid IngestURL with path": {
+			c: &Config{
+				BaseURL:   "https://example.com",
+				IngestURL: "https://ingest.example.com/api/v1",
+				APIKey:    uuid.NewString(),
+				Client:    &http.Client{},
+			},
+			wantErr: true,
+		},
 		"empty APIKey": {
 			c: &Config{
 				BaseURL: "https://example.com",
@@ -60,6 +79,33 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		"negative Timeout": {
+			c: &Config{
+				BaseURL: "https://example.com",
+				APIKey:  uuid.NewString(),
+				Client:  &http.Client{},
+				Timeout: -time.Second,
+			},
+			wantErr: true,
+		},
+		"invalid Retry": {
+			c: &Config{
+				BaseURL: "https://example.com",
+				APIKey:  uuid.NewString(),
+				Client:  &http.Client{},
+				Retry:   &RetryPolicy{MaxAttempts: 0},
+			},
+			wantErr: true,
+		},
+		"invalid WebhookPublicKey": {
+			c: &Config{
+				BaseURL:          "https://example.com",
+				APIKey:           uuid.NewString(),
+				Client:           &http.Client{},
+				WebhookPublicKey: "not a key",
+			},
+			wantErr: true,
+		},
 	}
 
 	for name, tc := range tt {
@@ -2,7 +2,6 @@ package lago
 
 import (
 	"context"
-	"fmt"
 	"net/url"
 	"strconv"
 	"time"
@@ -189,7 +188,7 @@ type AppliedCoupon struct {
 }
 
 func (c *Client) GetCoupon(ctx context.Context, couponCode string) (*Coupon, error) {
-	u := c.url("coupons/"+couponCode, nil)
+	u := c.url("GetCoupon", "coupons/{code}", nil, couponCode)
 
 	result, err := get[couponResult](ctx, c, u)
 	if err != nil {
@@ -200,12 +199,12 @@ func (c *Client) GetCoupon(ctx context.Context, couponCode string) (*Coupon, err
 }
 
 func (c *Client) ListCoupons(ctx context.Context, couponListInput *CouponListInput) (*CouponList, error) {
-	u := c.url("coupons", couponListInput.query())
+	u := c.url("ListCoupons", "coupons", couponListInput.query())
 	return get[CouponList](ctx, c, u)
 }
 
 func (c *Client) CreateCoupon(ctx context.Context, couponInput *CouponInput) (*Coupon, error) {
-	u := c.url("coupons", nil)
+	u := c.url("CreateCoupon", "coupons", nil)
 
 	result, err := post[couponParams, couponResult](
 		ctx,
@@ -221,7 +220,7 @@ func (c *Client) CreateCoupon(ctx context.Context, couponInput *CouponInput) (*C
 }
 
 func (c *Client) UpdateCoupon(ctx context.Context, couponInput *CouponInput) (*Coupon, error) {
-	u := c.url("coupons/"+couponInput.Code, nil)
+	u := c.url("UpdateCoupon", "coupons/{code}", nil, couponInput.Code)
 
 	result, err := put[couponParams, couponResult](
 		ctx,
@@ -237,7 +236,7 @@ func (c *Client) UpdateCoupon(ctx context.Context, couponInput *CouponInput) (*C
 }
 
 func (c *Client) DeleteCoupon(ctx context.Context, couponCode string) (*Coupon, error) {
-	u := c.url("coupons/"+couponCode, nil)
+	u := c.url("DeleteCoupon", "coupons/{code}", nil, couponCode)
 
 	result, err := delete[couponResult](ctx, c, u)
 	if err != nil {
@@ -248,12 +247,12 @@ func (c *Client) DeleteCoupon(ctx context.Context, couponCode string) (*Coupon,
 }
 
 func (c *Client) ListAppliedCoupons(ctx context.Context, appliedCouponListInput *AppliedCouponListInput) (*AppliedCouponList, error) {
-	u := c.url("applied_coupons", appliedCouponListInput.query())
+	u := c.url("ListAppliedCoupons", "applied_coupons", appliedCouponListInput.query())
 	return get[AppliedCouponList](ctx, c, u)
 }
 
 func (c *Client) ApplyCouponToCustomer(ctx context.Context, applyCouponInput *ApplyCouponInput) (*AppliedCoupon, error) {
-	u := c.url("applied_coupons", nil)
+	u := c.url("ApplyCouponToCustomer", "applied_coupons", nil)
 	result, err := post[applyCouponParams, appliedCouponResult](
 		ctx,
 		c,
@@ -268,8 +267,7 @@ func (c *Client) ApplyCouponToCustomer(ctx context.Context, applyCouponInput *Ap
 }
 
 func (ac *Client) DeleteAppliedCoupon(ctx context.Context, externalCustomerID string, appliedCouponID string) (*AppliedCoupon, error) {
-	subPath := fmt.Sprintf("customers/%s/applied_coupons/%s", externalCustomerID, appliedCouponID)
-	u := ac.url(subPath, nil)
+	u := ac.url("DeleteAppliedCoupon", "customers/{external_customer_id}/applied_coupons/{applied_coupon_id}", nil, externalCustomerID, appliedCouponID)
 
 	result, err := delete[appliedCouponResult](ctx, ac, u)
 	if err != nil {
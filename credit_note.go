@@ -2,7 +2,6 @@ package lago
 
 import (
 	"context"
-	"fmt"
 	"net/url"
 	"strconv"
 	"time"
@@ -186,7 +185,7 @@ type creditNoteEstimateParams struct {
 }
 
 func (c *Client) GetCreditNote(ctx context.Context, creditNoteID uuid.UUID) (*CreditNote, error) {
-	u := fmt.Sprintf("credit_notes/%s", creditNoteID)
+	u := c.url("GetCreditNote", "credit_notes/{lago_id}", nil, creditNoteID.String())
 	result, err := get[creditNoteResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -195,7 +194,7 @@ func (c *Client) GetCreditNote(ctx context.Context, creditNoteID uuid.UUID) (*Cr
 }
 
 func (c *Client) DownloadCreditNote(ctx context.Context, creditNoteID string) (*CreditNote, error) {
-	u := c.url("credit_notes/"+creditNoteID+"/download", nil)
+	u := c.url("DownloadCreditNote", "credit_notes/{lago_id}/download", nil, creditNoteID)
 	result, err := postWithoutBody[creditNoteResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -205,12 +204,12 @@ func (c *Client) DownloadCreditNote(ctx context.Context, creditNoteID string) (*
 }
 
 func (c *Client) ListCreditNotes(ctx context.Context, creditNoteListInput *CreditListInput) (*CreditNoteList, error) {
-	u := c.url("credit_notes", creditNoteListInput.query())
+	u := c.url("ListCreditNotes", "credit_notes", creditNoteListInput.query())
 	return get[CreditNoteList](ctx, c, u)
 }
 
 func (c *Client) CreateCreditNote(ctx context.Context, creditNoteInput *CreditNoteInput) (*CreditNote, error) {
-	u := c.url("credit_notes", nil)
+	u := c.url("CreateCreditNote", "credit_notes", nil)
 	result, err := post[creditNoteParams, creditNoteResult](
 		ctx,
 		c,
@@ -225,7 +224,7 @@ func (c *Client) CreateCreditNote(ctx context.Context, creditNoteInput *CreditNo
 }
 
 func (c *Client) UpdateCreditNote(ctx context.Context, creditNoteUpdateInput *CreditNoteUpdateInput) (*CreditNote, error) {
-	u := c.url("credit_notes/"+creditNoteUpdateInput.LagoID, nil)
+	u := c.url("UpdateCreditNote", "credit_notes/{lago_id}", nil, creditNoteUpdateInput.LagoID)
 
 	result, err := put[creditNoteUpdateParams, creditNoteResult](
 		ctx,
@@ -241,7 +240,7 @@ func (c *Client) UpdateCreditNote(ctx context.Context, creditNoteUpdateInput *Cr
 }
 
 func (c *Client) VoidCreditNote(ctx context.Context, creditNoteID string) (*CreditNote, error) {
-	u := c.url("credit_notes/"+creditNoteID+"/void", nil)
+	u := c.url("VoidCreditNote", "credit_notes/{lago_id}/void", nil, creditNoteID)
 	result, err := putWithoutBody[creditNoteResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -251,7 +250,7 @@ func (c *Client) VoidCreditNote(ctx context.Context, creditNoteID string) (*Cred
 }
 
 func (c *Client) EstimateCreditNote(ctx context.Context, creditNoteEstimateInput *CreditNoteEstimateInput) (*CreditNoteEstimated, error) {
-	u := c.url("credit_notes/estimate", nil)
+	u := c.url("EstimateCreditNote", "credit_notes/estimate", nil)
 
 	result, err := post[creditNoteEstimateParams, CreditNoteEstimatedResult](
 		ctx,
@@ -2,6 +2,8 @@ package lago
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
 	"time"
@@ -15,6 +17,7 @@ const (
 	PaymentProviderAdyen      CustomerPaymentProvider = "adyen"
 	PaymentProviderStripe     CustomerPaymentProvider = "stripe"
 	PaymentProviderGocardless CustomerPaymentProvider = "gocardless"
+	PaymentProviderMoneyhash  CustomerPaymentProvider = "moneyhash"
 )
 
 type FinalizeZeroAmountInvoice string
@@ -112,6 +115,7 @@ type CustomerInput struct {
 	IntegrationCustomers      []*IntegrationCustomer            `json:"integration_customers,omitempty"`
 	TaxCodes                  []string                          `json:"tax_codes,omitempty"`
 	FinalizeZeroAmountInvoice FinalizeZeroAmountInvoice         `json:"finalize_zero_amount_invoice,omitempty"`
+	BillingEntityCode         string                            `json:"billing_entity_code,omitempty"`
 }
 
 type CustomerListInput struct {
@@ -133,22 +137,37 @@ func (i *CustomerListInput) query() url.Values {
 }
 
 type CustomerBillingConfigurationInput struct {
-	InvoiceGracePeriod  int                     `json:"invoice_grace_period,omitempty"`
-	PaymentProvider     CustomerPaymentProvider `json:"payment_provider,omitempty"`
-	PaymentProviderCode string                  `json:"payment_provider_code,omitempty"`
-	ProviderCustomerID  string                  `json:"provider_customer_id,omitempty"`
-	Sync                bool                    `json:"sync,omitempty"`
-	SyncWithProvider    bool                    `json:"sync_with_provider,omitempty"`
-	DocumentLocale      string                  `json:"document_locale,omitempty"`
+	InvoiceGracePeriod     int                     `json:"invoice_grace_period,omitempty"`
+	PaymentProvider        CustomerPaymentProvider `json:"payment_provider,omitempty"`
+	PaymentProviderCode    string                  `json:"payment_provider_code,omitempty"`
+	ProviderCustomerID     string                  `json:"provider_customer_id,omitempty"`
+	Sync                   bool                    `json:"sync,omitempty"`
+	SyncWithProvider       bool                    `json:"sync_with_provider,omitempty"`
+	DocumentLocale         string                  `json:"document_locale,omitempty"`
+	ProviderPaymentMethods []string                `json:"provider_payment_methods,omitempty"`
 }
 
 type CustomerBillingConfiguration struct {
-	InvoiceGracePeriod  int                     `json:"invoice_grace_period,omitempty"`
-	PaymentProvider     CustomerPaymentProvider `json:"payment_provider,omitempty"`
-	PaymentProviderCode string                  `json:"payment_provider_code,omitempty"`
-	ProviderCustomerID  string                  `json:"provider_customer_id,omitempty"`
-	SyncWithProvider    bool                    `json:"sync_with_provider,omitempty"`
-	DocumentLocale      string                  `json:"document_locale,omitempty"`
+	InvoiceGracePeriod     int                     `json:"invoice_grace_period,omitempty"`
+	PaymentProvider        CustomerPaymentProvider `json:"payment_provider,omitempty"`
+	PaymentProviderCode    string                  `json:"payment_provider_code,omitempty"`
+	ProviderCustomerID     string                  `json:"provider_customer_id,omitempty"`
+	SyncWithProvider       bool                    `json:"sync_with_provider,omitempty"`
+	DocumentLocale         string                  `json:"document_locale,omitempty"`
+	ProviderPaymentMethods []string                `json:"provider_payment_methods,omitempty"`
+}
+
+// CustomerPaymentProviderInput attaches a payment provider to a customer.
+//
+// ProviderCustomerID links an existing customer of the provider. Otherwise,
+// SyncWithProvider creates the customer in the provider. ProviderPaymentMethods
+// only applies to Stripe, such as "card" or "sepa_debit".
+type CustomerPaymentProviderInput struct {
+	PaymentProvider        CustomerPaymentProvider
+	PaymentProviderCode    string
+	ProviderCustomerID     string
+	SyncWithProvider       bool
+	ProviderPaymentMethods []string
 }
 
 type Address struct {
@@ -227,6 +246,17 @@ type CustomerCheckoutURL struct {
 	CheckoutURL string `json:"checkout_url,omitempty"`
 }
 
+// CustomerCheckoutURLInput selects the payment provider the checkout URL is
+// generated for. The customer's payment provider is used when it is empty.
+type CustomerCheckoutURLInput struct {
+	PaymentProvider     CustomerPaymentProvider `json:"payment_provider,omitempty"`
+	PaymentProviderCode string                  `json:"payment_provider_code,omitempty"`
+}
+
+type customerCheckoutURLParams struct {
+	Customer *CustomerCheckoutURLInput `json:"customer"`
+}
+
 type CustomerUsageInput struct {
 	ExternalSubscriptionID string `json:"external_subscription_id,omitempty"`
 }
@@ -293,6 +323,7 @@ type Customer struct {
 	Currency                  Currency                        `json:"currency,omitempty"`
 	Timezone                  string                          `json:"timezone,omitempty"`
 	ApplicableTimezone        string                          `json:"applicable_timezone,omitempty"`
+	BillingEntityCode         string                          `json:"billing_entity_code,omitempty"`
 
 	Taxes []*Tax `json:"taxes,omitempty"`
 
@@ -301,7 +332,7 @@ type Customer struct {
 }
 
 func (c *Client) CreateCustomer(ctx context.Context, customerInput *CustomerInput) (*Customer, error) {
-	u := c.url("customers", nil)
+	u := c.url("CreateCustomer", "customers", nil)
 	result, err := post[customerParams, customerResult](
 		ctx,
 		c,
@@ -323,7 +354,7 @@ func (c *Client) UpdateCustomer(ctx context.Context, customerInput *CustomerInpu
 }
 
 func (c *Client) GetCustomersCurrentUsage(ctx context.Context, externalCustomerID string, customerUsageInput *CustomerUsageInput) (*CustomerUsage, error) {
-	u := c.url("customers/"+externalCustomerID+"/current_usage", customerUsageInput.query())
+	u := c.url("GetCustomersCurrentUsage", "customers/{external_customer_id}/current_usage", customerUsageInput.query(), externalCustomerID)
 
 	result, err := get[CustomerUsageResult](ctx, c, u)
 	if err != nil {
@@ -334,13 +365,13 @@ func (c *Client) GetCustomersCurrentUsage(ctx context.Context, externalCustomerI
 }
 
 func (c *Client) ListCustomersPastUsage(ctx context.Context, externalCustomerID string, customerPastUsageInput *CustomerPastUsageInput) (*CustomerPastUsageList, error) {
-	u := c.url("customers/"+externalCustomerID+"/past_usage", customerPastUsageInput.query())
+	u := c.url("ListCustomersPastUsage", "customers/{external_customer_id}/past_usage", customerPastUsageInput.query(), externalCustomerID)
 
 	return get[CustomerPastUsageList](ctx, c, u)
 }
 
 func (c *Client) GetCustomersPortalURL(ctx context.Context, externalCustomerID string) (*CustomerPortalURL, error) {
-	u := c.url("customers/"+externalCustomerID+"/portal_url", nil)
+	u := c.url("GetCustomersPortalURL", "customers/{external_customer_id}/portal_url", nil, externalCustomerID)
 	result, err := get[CustomerPortalURLResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -350,7 +381,7 @@ func (c *Client) GetCustomersPortalURL(ctx context.Context, externalCustomerID s
 }
 
 func (c *Client) GetCustomersCheckoutURL(ctx context.Context, externalCustomerID string) (*CustomerCheckoutURL, error) {
-	u := c.url("customers/"+externalCustomerID+"/checkout_url", nil)
+	u := c.url("GetCustomersCheckoutURL", "customers/{external_customer_id}/checkout_url", nil, externalCustomerID)
 	result, err := get[CustomerCheckoutURLResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -359,8 +390,47 @@ func (c *Client) GetCustomersCheckoutURL(ctx context.Context, externalCustomerID
 	return result.CustomerCheckoutURL, nil
 }
 
+// SetCustomerPaymentProvider attaches the payment provider to the customer,
+// replacing the current one.
+func (c *Client) SetCustomerPaymentProvider(ctx context.Context, externalCustomerID string, paymentProviderInput *CustomerPaymentProviderInput) (*Customer, error) {
+	if paymentProviderInput.PaymentProvider == "" {
+		return nil, errors.New("payment provider is required")
+	}
+	if len(paymentProviderInput.ProviderPaymentMethods) > 0 && paymentProviderInput.PaymentProvider != PaymentProviderStripe {
+		return nil, fmt.Errorf("provider payment methods are not supported by %s", paymentProviderInput.PaymentProvider)
+	}
+
+	return c.CreateCustomer(ctx, &CustomerInput{
+		ExternalID: externalCustomerID,
+		BillingConfiguration: CustomerBillingConfigurationInput{
+			PaymentProvider:        paymentProviderInput.PaymentProvider,
+			PaymentProviderCode:    paymentProviderInput.PaymentProviderCode,
+			ProviderCustomerID:     paymentProviderInput.ProviderCustomerID,
+			SyncWithProvider:       paymentProviderInput.SyncWithProvider,
+			ProviderPaymentMethods: paymentProviderInput.ProviderPaymentMethods,
+		},
+	})
+}
+
+// RegenerateCustomersCheckoutURL generates a new checkout URL for the
+// customer, for the payment provider chosen by the input.
+func (c *Client) RegenerateCustomersCheckoutURL(ctx context.Context, externalCustomerID string, checkoutURLInput *CustomerCheckoutURLInput) (*CustomerCheckoutURL, error) {
+	u := c.url("RegenerateCustomersCheckoutURL", "customers/{external_customer_id}/checkout_url", nil, externalCustomerID)
+	result, err := post[customerCheckoutURLParams, CustomerCheckoutURLResult](
+		ctx,
+		c,
+		u,
+		&customerCheckoutURLParams{Customer: checkoutURLInput},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.CustomerCheckoutURL, nil
+}
+
 func (c *Client) DeleteCustomer(ctx context.Context, externalCustomerID string) (*Customer, error) {
-	u := c.url("customers/"+externalCustomerID, nil)
+	u := c.url("DeleteCustomer", "customers/{external_customer_id}", nil, externalCustomerID)
 	result, err := delete[customerResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -370,7 +440,7 @@ func (c *Client) DeleteCustomer(ctx context.Context, externalCustomerID string)
 }
 
 func (c *Client) GetCustomer(ctx context.Context, externalCustomerID string) (*Customer, error) {
-	u := c.url("customers/"+externalCustomerID, nil)
+	u := c.url("GetCustomer", "customers/{external_customer_id}", nil, externalCustomerID)
 	result, err := get[customerResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -380,6 +450,48 @@ func (c *Client) GetCustomer(ctx context.Context, externalCustomerID string) (*C
 }
 
 func (c *Client) ListCustomers(ctx context.Context, customerListInput *CustomerListInput) (*CustomerList, error) {
-	u := c.url("customers", customerListInput.query())
+	u := c.url("ListCustomers", "customers", customerListInput.query())
 	return get[CustomerList](ctx, c, u)
 }
+
+// ListCustomerInvoices lists the invoices of the customer.
+// The ExternalCustomerID of the input is ignored.
+func (c *Client) ListCustomerInvoices(ctx context.Context, externalCustomerID string, invoiceListInput *InvoiceListInput) (*InvoiceList, error) {
+	u := c.url("ListCustomerInvoices", "customers/{external_customer_id}/invoices", customerScopedQuery(invoiceListInput.query()), externalCustomerID)
+	return get[InvoiceList](ctx, c, u)
+}
+
+// ListCustomerSubscriptions lists the subscriptions of the customer.
+// The ExternalCustomerID of the input is ignored.
+func (c *Client) ListCustomerSubscriptions(ctx context.Context, externalCustomerID string, subscriptionListInput *SubscriptionListInput) (*SubscriptionList, error) {
+	u := c.url("ListCustomerSubscriptions", "customers/{external_customer_id}/subscriptions", customerScopedQuery(subscriptionListInput.query()), externalCustomerID)
+	return get[SubscriptionList](ctx, c, u)
+}
+
+// ListCustomerWallets lists the wallets of the customer.
+// The ExternalCustomerID of the input is ignored.
+func (c *Client) ListCustomerWallets(ctx context.Context, externalCustomerID string, walletListInput *WalletListInput) (*WalletList, error) {
+	u := c.url("ListCustomerWallets", "customers/{external_customer_id}/wallets", customerScopedQuery(walletListInput.query()), externalCustomerID)
+	return get[WalletList](ctx, c, u)
+}
+
+// ListCustomerAppliedCoupons lists the coupons applied to the customer.
+// The ExternalCustomerID of the input is ignored.
+func (c *Client) ListCustomerAppliedCoupons(ctx context.Context, externalCustomerID string, appliedCouponListInput *AppliedCouponListInput) (*AppliedCouponList, error) {
+	u := c.url("ListCustomerAppliedCoupons", "customers/{external_customer_id}/applied_coupons", customerScopedQuery(appliedCouponListInput.query()), externalCustomerID)
+	return get[AppliedCouponList](ctx, c, u)
+}
+
+// ListCustomerCreditNotes lists the credit notes of the customer.
+// The ExternalCustomerID of the input is ignored.
+func (c *Client) ListCustomerCreditNotes(ctx context.Context, externalCustomerID string, creditNoteListInput *CreditListInput) (*CreditNoteList, error) {
+	u := c.url("ListCustomerCreditNotes", "customers/{external_customer_id}/credit_notes", customerScopedQuery(creditNoteListInput.query()), externalCustomerID)
+	return get[CreditNoteList](ctx, c, u)
+}
+
+// customerScopedQuery removes the customer filter of a list query, since the
+// customer is part of the path.
+func customerScopedQuery(q url.Values) url.Values {
+	q.Del("external_customer_id")
+	return q
+}
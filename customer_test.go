@@ -0,0 +1,91 @@
+package lago
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestClient_CustomerSubResources(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]requestTest{
+		"invoices": {
+			call: func(c *Client) error {
+				list, err := c.ListCustomerInvoices(ctx, "cus", &InvoiceListInput{ExternalCustomerID: "other", Page: 2})
+				if err == nil && len(list.Invoices) != 2 {
+					t.Errorf("got %d invoices, want 2", len(list.Invoices))
+				}
+				return err
+			},
+			response:   `{"invoices":[{"number":"1"},{"number":"2"}],"meta":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/customers/cus/invoices?page=2",
+		},
+		"subscriptions": {
+			call: func(c *Client) error {
+				list, err := c.ListCustomerSubscriptions(ctx, "cus", &SubscriptionListInput{})
+				if err == nil && len(list.Subscriptions) != 1 {
+					t.Errorf("got %d subscriptions, want 1", len(list.Subscriptions))
+				}
+				return err
+			},
+			response:   `{"subscriptions":[{"external_id":"sub"}],"meta":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/customers/cus/subscriptions",
+		},
+		"wallets": {
+			call: func(c *Client) error {
+				list, err := c.ListCustomerWallets(ctx, "cus", &WalletListInput{PerPage: 5})
+				if err == nil && len(list.Wallets) != 2 {
+					t.Errorf("got %d wallets, want 2", len(list.Wallets))
+				}
+				return err
+			},
+			response:   `{"wallets":[{"name":"a"},{"name":"b"}],"meta":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/customers/cus/wallets?per_page=5",
+		},
+		"applied coupons": {
+			call: func(c *Client) error {
+				list, err := c.ListCustomerAppliedCoupons(ctx, "cus", &AppliedCouponListInput{Status: AppliedCouponStatusActive})
+				if err == nil && len(list.AppliedCoupons) != 1 {
+					t.Errorf("got %d applied coupons, want 1", len(list.AppliedCoupons))
+				}
+				return err
+			},
+			response:   `{"applied_coupons":[{"coupon_code":"a"}],"meta":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/customers/cus/applied_coupons?status=active",
+		},
+		"credit notes": {
+			call: func(c *Client) error {
+				list, err := c.ListCustomerCreditNotes(ctx, "cus", &CreditListInput{})
+				if err == nil && len(list.CreditNotes) != 1 {
+					t.Errorf("got %d credit notes, want 1", len(list.CreditNotes))
+				}
+				return err
+			},
+			response:   `{"credit_notes":[{"number":"1"}],"meta":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/customers/cus/credit_notes",
+		},
+		"regenerate checkout url": {
+			call: func(c *Client) error {
+				checkout, err := c.RegenerateCustomersCheckoutURL(ctx, "cus", &CustomerCheckoutURLInput{
+					PaymentProvider: PaymentProviderStripe,
+				})
+				if err == nil && checkout.CheckoutURL != "https://pay.example.com" {
+					t.Errorf("CheckoutURL = %q", checkout.CheckoutURL)
+				}
+				return err
+			},
+			response:   `{"customer":{"checkout_url":"https://pay.example.com"}}`,
+			wantMethod: http.MethodPost,
+			wantURI:    "/api/v1/customers/cus/checkout_url",
+			wantBody:   `{"customer":{"payment_provider":"stripe"}}`,
+		},
+	}
+
+	runRequestTests(t, tests)
+}
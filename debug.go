@@ -0,0 +1,129 @@
+package lago
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/nikola-jokic/lago-go/internal/redact"
+)
+
+// DefaultRedactedFields are the JSON fields redacted from the bodies logged
+// in debug mode when Config.RedactedFields is nil.
+var DefaultRedactedFields = []string{
+	"email",
+	"phone",
+	"address_line1",
+	"address_line2",
+	"zipcode",
+	"tax_identification_number",
+	"legal_number",
+}
+
+const (
+	redacted = "[REDACTED]"
+
+	// maxLoggedBodySize truncates the bodies logged in debug mode.
+	maxLoggedBodySize = 64 << 10
+)
+
+func newDebugLogger(cfg *Config) *slog.Logger {
+	if cfg.Logger != nil {
+		return cfg.Logger
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func newRedactedFields(fields []string) map[string]bool {
+	if fields == nil {
+		fields = DefaultRedactedFields
+	}
+	m := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		m[f] = true
+	}
+	return m
+}
+
+// send performs a single attempt of the request, logging it in debug mode.
+func (c *Client) send(req *http.Request, attempt int) (*http.Response, error) {
+	if !c.debug {
+		return c.client.Do(req)
+	}
+
+	start := time.Now()
+	res, err := c.client.Do(req)
+	latency := time.Since(start)
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.Int("attempt", attempt),
+		slog.Duration("latency", latency),
+		slog.Any("request_headers", c.redactHeaders(req.Header)),
+	}
+	if body := requestBody(req); body != nil {
+		attrs = append(attrs, slog.String("request_body", c.redactBody(body)))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		c.logger.LogAttrs(req.Context(), slog.LevelDebug, "lago request failed", attrs...)
+		return res, err
+	}
+
+	// Buffer the body so it can be logged and still be read by the caller.
+	resBody, readErr := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	attrs = append(attrs,
+		slog.Int("status", res.StatusCode),
+		slog.String("response_body", c.redactBody(resBody)),
+	)
+	if readErr != nil {
+		attrs = append(attrs, slog.String("error", readErr.Error()))
+	}
+	c.logger.LogAttrs(req.Context(), slog.LevelDebug, "lago request", attrs...)
+
+	return res, readErr
+}
+
+func (c *Client) redactHeaders(h http.Header) http.Header {
+	h = h.Clone()
+	if h.Get("Authorization") != "" {
+		h.Set("Authorization", redacted)
+	}
+	return h
+}
+
+// redactBody replaces the values of the redacted fields, at any depth of
+// the JSON body, before truncating it. Bodies that are not JSON are logged
+// as they are.
+func (c *Client) redactBody(body []byte) string {
+	if b, ok := redact.JSON(body, c.redactedFields, redacted); ok {
+		body = b
+	}
+	if len(body) > maxLoggedBodySize {
+		return string(body[:maxLoggedBodySize]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// requestBody returns a copy of the request body, read with req.GetBody.
+func requestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	r, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+
+	body, _ := io.ReadAll(r)
+	return body
+}
@@ -1,11 +1,35 @@
 package lago
 
 import (
+	"cmp"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Sentinel errors matched by *HTTPError with errors.Is, based on the
+// response status code.
+var (
+	ErrUnauthorized = errors.New("lago: unauthorized")
+	ErrForbidden    = errors.New("lago: forbidden")
+	ErrNotFound     = errors.New("lago: not found")
+	ErrConflict     = errors.New("lago: conflict")
+	ErrValidation   = errors.New("lago: validation failed")
+	ErrRateLimited  = errors.New("lago: rate limited")
+	ErrServer       = errors.New("lago: server error")
+)
+
+// maxErrorBodySize limits how much of an undecodable error body is kept.
+const maxErrorBodySize = 1 << 10
+
+// maxErrorResponseSize limits how much of an error response is read.
+const maxErrorResponseSize = 1 << 20
+
 type ErrorDetail map[int]map[string][]string
 
 func (ed *ErrorDetail) UnmarshalJSON(data []byte) error {
@@ -28,27 +52,141 @@ func (ed *ErrorDetail) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// indexedErrorDetail reports whether the error details of the body are keyed
+// by item index, like {"1": {"code": ["value_already_exist"]}}, rather than
+// by field, like {"events": ["too_many_events"]}.
+func indexedErrorDetail(body []byte) bool {
+	var e struct {
+		ErrorDetail map[string]json.RawMessage `json:"error_details"`
+	}
+	if json.Unmarshal(body, &e) != nil || len(e.ErrorDetail) == 0 {
+		return false
+	}
+	for k := range e.ErrorDetail {
+		if _, err := strconv.Atoi(k); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func (ed ErrorDetail) MarshalJSON() ([]byte, error) {
 	return json.Marshal(map[int]map[string][]string(ed))
 }
 
+// Violation is a validation error reported for a field of the item at Index.
+// Index is always 0 for requests that are not batches.
+type Violation struct {
+	Index int
+	Field string
+	Codes []string
+}
+
+// Violations returns the violations ordered by index and field.
+func (ed ErrorDetail) Violations() []Violation {
+	var violations []Violation
+	for idx, fields := range ed {
+		for field, codes := range fields {
+			violations = append(violations, Violation{Index: idx, Field: field, Codes: codes})
+		}
+	}
+
+	slices.SortFunc(violations, func(a, b Violation) int {
+		if c := cmp.Compare(a.Index, b.Index); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Field, b.Field)
+	})
+	return violations
+}
+
 type HTTPError struct {
 	HTTPStatusCode int    `json:"status"`
 	Message        string `json:"error"`
 	ErrorCode      string `json:"code"`
 
 	ErrorDetail ErrorDetail `json:"error_details,omitempty"`
+	// IndexedErrorDetail reports whether ErrorDetail holds the errors of the
+	// items of a batch by index. Otherwise, the errors are about the whole
+	// request, and are held under index 0.
+	IndexedErrorDetail bool `json:"-"`
+
+	// Method and URL identify the request that failed.
+	Method string `json:"-"`
+	URL    string `json:"-"`
+	// RequestID is the X-Request-Id header of the response, if any.
+	RequestID string `json:"-"`
+	// RetryAfter is the delay requested by the Retry-After header, if any.
+	RetryAfter time.Duration `json:"-"`
+	// RawBody holds the beginning of the response body when it could not be
+	// decoded as JSON.
+	RawBody string `json:"-"`
 }
 
 func (e HTTPError) Error() string {
 	msg, _ := json.Marshal(&e)
-	return string(msg)
+	if e.Method == "" {
+		return string(msg)
+	}
+	return e.Method + " " + e.URL + ": " + string(msg)
+}
+
+// Violations returns the field level validation errors of the response.
+func (e HTTPError) Violations() []Violation {
+	return e.ErrorDetail.Violations()
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e HTTPError) Is(target error) bool {
+	switch code := e.HTTPStatusCode; target {
+	case ErrUnauthorized:
+		return code == http.StatusUnauthorized
+	case ErrForbidden:
+		return code == http.StatusForbidden
+	case ErrNotFound:
+		return code == http.StatusNotFound
+	case ErrConflict:
+		return code == http.StatusConflict
+	case ErrValidation:
+		return code == http.StatusUnprocessableEntity
+	case ErrRateLimited:
+		return code == http.StatusTooManyRequests
+	case ErrServer:
+		return code >= http.StatusInternalServerError
+	default:
+		return false
+	}
 }
 
-func readError(r io.Reader) error {
-	var e HTTPError
-	if err := json.NewDecoder(r).Decode(&e); err != nil {
-		return fmt.Errorf("failed to decode error response: %w", err)
+// readError builds the error for an unsuccessful response. The status code
+// is always the one of the response, even if the body is not JSON.
+func readError(res *http.Response) error {
+	e := HTTPError{
+		HTTPStatusCode: res.StatusCode,
+		RequestID:      res.Header.Get(RequestIDHeader),
 	}
+	if res.Request != nil {
+		e.Method = res.Request.Method
+		e.URL = res.Request.URL.String()
+	}
+	if d, ok := retryAfter(res.Header, time.Now()); ok {
+		e.RetryAfter = d
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorResponseSize))
+	if err := json.Unmarshal(body, &e); err != nil {
+		if len(body) > maxErrorBodySize {
+			body = body[:maxErrorBodySize]
+		}
+		e.RawBody = string(body)
+	} else {
+		e.IndexedErrorDetail = indexedErrorDetail(body)
+	}
+
+	e.HTTPStatusCode = res.StatusCode
+	if e.Message == "" {
+		e.Message = http.StatusText(res.StatusCode)
+	}
+
 	return &e
 }
@@ -2,6 +2,12 @@ package lago
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -128,3 +134,180 @@ func TestErrorDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestReadError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     http.Header
+		body       string
+		want       error
+		wantCode   string
+		wantRaw    string
+		wantReqID  string
+		wantStatus int
+		wantIndex  bool
+	}{
+		{
+			name:       "not found",
+			status:     http.StatusNotFound,
+			body:       `{"status":404,"error":"Not Found","code":"customer_not_found"}`,
+			want:       ErrNotFound,
+			wantCode:   "customer_not_found",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "validation",
+			status:     http.StatusUnprocessableEntity,
+			header:     http.Header{"X-Request-Id": {"req-1"}},
+			body:       `{"status":422,"error":"Unprocessable Entity","code":"validation_errors"}`,
+			want:       ErrValidation,
+			wantCode:   "validation_errors",
+			wantReqID:  "req-1",
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "batch validation",
+			status:     http.StatusUnprocessableEntity,
+			body:       `{"status":422,"code":"validation_errors","error_details":{"events":["too_many_events"]}}`,
+			want:       ErrValidation,
+			wantCode:   "validation_errors",
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "indexed validation",
+			status:     http.StatusUnprocessableEntity,
+			body:       `{"status":422,"code":"validation_errors","error_details":{"1":{"transaction_id":["value_already_exist"]}}}`,
+			want:       ErrValidation,
+			wantCode:   "validation_errors",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantIndex:  true,
+		},
+		{
+			name:       "status from response",
+			status:     http.StatusTooManyRequests,
+			body:       `{"status":500}`,
+			want:       ErrRateLimited,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "html body",
+			status:     http.StatusBadGateway,
+			body:       `<html>Bad Gateway</html>`,
+			want:       ErrServer,
+			wantRaw:    `<html>Bad Gateway</html>`,
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "unauthorized",
+			status:     http.StatusUnauthorized,
+			body:       ``,
+			want:       ErrUnauthorized,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = make(http.Header)
+			}
+			req := httptest.NewRequest(http.MethodGet, "https://example.com/api/v1/customers/cus", nil)
+			res := &http.Response{
+				StatusCode: tt.status,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+				Request:    req,
+			}
+
+			err := readError(res)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+			if errors.Is(err, ErrConflict) {
+				t.Errorf("errors.Is(%v, ErrConflict) = true", err)
+			}
+
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("errors.As(%v, *HTTPError) = false", err)
+			}
+			if httpErr.HTTPStatusCode != tt.wantStatus {
+				t.Errorf("HTTPStatusCode = %d, want %d", httpErr.HTTPStatusCode, tt.wantStatus)
+			}
+			if httpErr.ErrorCode != tt.wantCode {
+				t.Errorf("ErrorCode = %q, want %q", httpErr.ErrorCode, tt.wantCode)
+			}
+			if httpErr.RawBody != tt.wantRaw {
+				t.Errorf("RawBody = %q, want %q", httpErr.RawBody, tt.wantRaw)
+			}
+			if httpErr.IndexedErrorDetail != tt.wantIndex {
+				t.Errorf("IndexedErrorDetail = %v, want %v", httpErr.IndexedErrorDetail, tt.wantIndex)
+			}
+			if httpErr.RequestID != tt.wantReqID {
+				t.Errorf("RequestID = %q, want %q", httpErr.RequestID, tt.wantReqID)
+			}
+			if httpErr.Method != http.MethodGet || httpErr.URL != req.URL.String() {
+				t.Errorf("request = %s %s, want %s %s", httpErr.Method, httpErr.URL, http.MethodGet, req.URL)
+			}
+		})
+	}
+}
+
+// countingReader counts the bytes read from it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestReadError_LargeBody(t *testing.T) {
+	body := &countingReader{r: strings.NewReader(strings.Repeat("x", 2*maxErrorResponseSize))}
+	res := &http.Response{
+		StatusCode: http.StatusBadGateway,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(body),
+	}
+
+	var httpErr *HTTPError
+	if err := readError(res); !errors.As(err, &httpErr) {
+		t.Fatalf("errors.As(%v, *HTTPError) = false", err)
+	}
+	if body.n > maxErrorResponseSize {
+		t.Errorf("read %d bytes, want at most %d", body.n, maxErrorResponseSize)
+	}
+	if len(httpErr.RawBody) != maxErrorBodySize {
+		t.Errorf("len(RawBody) = %d, want %d", len(httpErr.RawBody), maxErrorBodySize)
+	}
+}
+
+func TestErrorDetail_Violations(t *testing.T) {
+	ed := ErrorDetail{
+		2: {"code": {"value_is_mandatory"}},
+		0: {
+			"transaction_id": {"value_already_exist"},
+			"code":           {"value_is_invalid"},
+		},
+	}
+
+	got := ed.Violations()
+	want := []Violation{
+		{Index: 0, Field: "code", Codes: []string{"value_is_invalid"}},
+		{Index: 0, Field: "transaction_id", Codes: []string{"value_already_exist"}},
+		{Index: 2, Field: "code", Codes: []string{"value_is_mandatory"}},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Violations() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Index != want[i].Index || got[i].Field != want[i].Field || !slices.Equal(got[i].Codes, want[i].Codes) {
+			t.Errorf("Violations()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -2,6 +2,7 @@ package lago
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -56,7 +57,7 @@ type Event struct {
 }
 
 func (c *Client) CreateEvent(ctx context.Context, eventInput *EventInput) (*Event, error) {
-	u := c.url("events", nil)
+	u := c.ingest("CreateEvent", "events", nil)
 	result, err := post[eventParams, EventResult](
 		ctx,
 		c,
@@ -71,7 +72,7 @@ func (c *Client) CreateEvent(ctx context.Context, eventInput *EventInput) (*Even
 }
 
 func (c *Client) EstimateEventFees(ctx context.Context, estimateInput *EventEstimateFeesInput) (*feeResult, error) {
-	u := c.url("events/estimate_fees", nil)
+	u := c.url("EstimateEventFees", "events/estimate_fees", nil)
 	return post[eventEstimateFeesParams, feeResult](
 		ctx,
 		c,
@@ -81,7 +82,7 @@ func (c *Client) EstimateEventFees(ctx context.Context, estimateInput *EventEsti
 }
 
 func (c *Client) GetEvent(ctx context.Context, eventID string) (*Event, error) {
-	u := c.url("events/"+eventID, nil)
+	u := c.url("GetEvent", "events/{transaction_id}", nil, eventID)
 	result, err := get[EventResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -91,7 +92,7 @@ func (c *Client) GetEvent(ctx context.Context, eventID string) (*Event, error) {
 }
 
 func (c *Client) BatchEvents(ctx context.Context, batchInput *[]*EventInput) (*[]*Event, error) {
-	u := c.url("events/batch", nil)
+	u := c.ingest("BatchEvents", "events/batch", nil)
 	result, err := post[batchEventParams, BatchEventResult](
 		ctx,
 		c,
@@ -104,3 +105,57 @@ func (c *Client) BatchEvents(ctx context.Context, batchInput *[]*EventInput) (*[
 
 	return result.Events, nil
 }
+
+// RejectedEvent is an event of a batch rejected by the batch endpoint.
+type RejectedEvent struct {
+	Event *EventInput
+	// Index is the position of the event in the batch.
+	Index int
+	// Violations are the validation errors reported for the event, or for
+	// the whole batch when it was rejected as a whole. They are empty when
+	// the batch failed for another reason.
+	Violations []Violation
+}
+
+// SplitBatchEvents splits the batch sent to BatchEvents according to the
+// error it returned. Events without validation errors are returned as
+// accepted, so they can be sent again without the rejected ones.
+//
+// When err is nil every event is accepted. When err does not report
+// validation errors per event, such as a batch with too many events, every
+// event is rejected.
+func SplitBatchEvents(batch []*EventInput, err error) (accepted []*EventInput, rejected []*RejectedEvent) {
+	if err == nil {
+		return batch, nil
+	}
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || !errors.Is(httpErr, ErrValidation) || len(httpErr.ErrorDetail) == 0 {
+		for idx, event := range batch {
+			rejected = append(rejected, &RejectedEvent{Event: event, Index: idx})
+		}
+		return nil, rejected
+	}
+
+	if !httpErr.IndexedErrorDetail {
+		violations := httpErr.Violations()
+		for idx, event := range batch {
+			rejected = append(rejected, &RejectedEvent{Event: event, Index: idx, Violations: violations})
+		}
+		return nil, rejected
+	}
+
+	violations := make(map[int][]Violation)
+	for _, v := range httpErr.Violations() {
+		violations[v.Index] = append(violations[v.Index], v)
+	}
+
+	for idx, event := range batch {
+		if v, ok := violations[idx]; ok {
+			rejected = append(rejected, &RejectedEvent{Event: event, Index: idx, Violations: v})
+			continue
+		}
+		accepted = append(accepted, event)
+	}
+	return accepted, rejected
+}
@@ -0,0 +1,436 @@
+package lago
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBatchEvents is the maximum number of events accepted by the batch
+// endpoint in a single request.
+const MaxBatchEvents = 100
+
+// maxEventRetryDelay caps the backoff between two attempts to send the
+// spooled events that failed for a transient reason.
+const maxEventRetryDelay = time.Minute
+
+var (
+	// ErrEventQueueFull is returned by EventIngester.Enqueue when the queue
+	// has no room for the event.
+	ErrEventQueueFull = errors.New("event queue is full")
+	// ErrEventIngesterClosed is returned by EventIngester.Enqueue after the
+	// ingester is closed.
+	ErrEventIngesterClosed = errors.New("event ingester is closed")
+)
+
+// EventIngesterConfig configures an EventIngester. Zero values are replaced
+// with the defaults documented on each field.
+type EventIngesterConfig struct {
+	// BatchSize is the maximum number of events sent in one batch.
+	// It defaults to, and cannot exceed, MaxBatchEvents.
+	BatchSize int
+	// MaxBatchBytes is the maximum JSON encoded size of the events sent in
+	// one batch. It defaults to 1 MiB.
+	MaxBatchBytes int
+	// FlushInterval is the longest time an event waits in a partial batch.
+	// It defaults to 1 second.
+	FlushInterval time.Duration
+	// Senders is the number of batches sent concurrently. It defaults to 1.
+	Senders int
+	// QueueSize is the number of events that can be queued before Enqueue
+	// starts rejecting them. It defaults to 10000.
+	QueueSize int
+	// OnError is called for every event that is dropped: rejected for good
+	// by the API, or failed for another reason without a Spool to send it
+	// again from. Events kept in the Spool are not reported, since they are
+	// sent again. It is called from the sender goroutines, so it must be safe
+	// for concurrent use.
+	OnError func(event *EventInput, err error)
+	// Spool, when set, stores every event on disk before it is queued and
+	// until the batch endpoint confirms it. Events left in the spool by a
+	// previous process are sent first, and events that failed for a transient
+	// reason are sent again with an exponential backoff, starting at
+	// FlushInterval. The spool is not closed by Close.
+	Spool *EventSpool
+}
+
+func (c *EventIngesterConfig) Validate() error {
+	if c.BatchSize < 0 || c.BatchSize > MaxBatchEvents {
+		return fmt.Errorf("BatchSize must be between 1 and %d", MaxBatchEvents)
+	}
+	if c.MaxBatchBytes < 0 {
+		return errors.New("MaxBatchBytes must not be negative")
+	}
+	if c.FlushInterval < 0 {
+		return errors.New("FlushInterval must not be negative")
+	}
+	if c.Senders < 0 {
+		return errors.New("Senders must not be negative")
+	}
+	if c.QueueSize < 0 {
+		return errors.New("QueueSize must not be negative")
+	}
+	return nil
+}
+
+func (c *EventIngesterConfig) withDefaults() EventIngesterConfig {
+	cfg := *c
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = MaxBatchEvents
+	}
+	if cfg.MaxBatchBytes == 0 {
+		cfg.MaxBatchBytes = 1 << 20
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.Senders == 0 {
+		cfg.Senders = 1
+	}
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(*EventInput, error) {}
+	}
+	return cfg
+}
+
+// EventIngester sends events asynchronously through the batch endpoint.
+// Events are grouped in batches by count and size and flushed at least every
+// FlushInterval. It is safe for concurrent use by multiple goroutines.
+type EventIngester struct {
+	client *Client
+	cfg    EventIngesterConfig
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan queuedEvent
+
+	batches chan []queuedEvent
+	wg      sync.WaitGroup
+
+	// retries are the spooled events to send again at retryAt, after
+	// failing for a transient reason.
+	retryMu    sync.Mutex
+	retries    []queuedEvent
+	retryAt    time.Time
+	retryDelay time.Duration
+
+	// ctx is cancelled when Close gives up waiting for pending batches.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type queuedEvent struct {
+	event  *EventInput
+	size   int
+	record spoolRecord
+}
+
+// NewEventIngester starts an ingester sending events with the client.
+// Close must be called to flush the pending events and stop it.
+func (c *Client) NewEventIngester(cfg EventIngesterConfig) (*EventIngester, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg = cfg.withDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	i := &EventIngester{
+		client:  c,
+		cfg:     cfg,
+		queue:   make(chan queuedEvent, cfg.QueueSize),
+		batches: make(chan []queuedEvent, cfg.Senders),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	i.wg.Add(1 + cfg.Senders)
+	go i.batch()
+	for range cfg.Senders {
+		go i.send()
+	}
+
+	return i, nil
+}
+
+// Enqueue queues the event without blocking. An empty TransactionID is set
+// to a random one, so the event is deduplicated if its batch is retried.
+func (i *EventIngester) Enqueue(event *EventInput) error {
+	if event.TransactionID == "" {
+		event.TransactionID = uuid.NewString()
+	}
+
+	b, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if i.closed {
+		return ErrEventIngesterClosed
+	}
+
+	qe := queuedEvent{event: event, size: len(b)}
+	if i.cfg.Spool != nil {
+		if qe.record, err = i.cfg.Spool.append(b); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case i.queue <- qe:
+		return nil
+	default:
+		if i.cfg.Spool != nil {
+			// The caller is told the event was dropped, so it must not be
+			// replayed. A failed acknowledgement only risks a duplicate.
+			_ = i.cfg.Spool.ack(qe.record)
+		}
+		return ErrEventQueueFull
+	}
+}
+
+// Close stops accepting events and waits until the queued events are sent.
+// When ctx is done first, the pending requests are cancelled, their events
+// are reported to OnError unless they are kept in the spool, and the context
+// error is returned.
+func (i *EventIngester) Close(ctx context.Context) error {
+	i.mu.Lock()
+	if !i.closed {
+		i.closed = true
+		close(i.queue)
+	}
+	i.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		i.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		i.cancel()
+		return nil
+	case <-ctx.Done():
+		i.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// batch groups queued events into batches until the queue is closed.
+func (i *EventIngester) batch() {
+	defer i.wg.Done()
+	defer close(i.batches)
+
+	ticker := time.NewTicker(i.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	var (
+		batch []queuedEvent
+		size  int
+	)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		i.batches <- batch
+		batch, size = nil, 0
+	}
+	add := func(qe queuedEvent) {
+		if len(batch) > 0 && size+qe.size > i.cfg.MaxBatchBytes {
+			flush()
+		}
+		batch = append(batch, qe)
+		size += qe.size
+		if len(batch) >= i.cfg.BatchSize || size >= i.cfg.MaxBatchBytes {
+			flush()
+		}
+	}
+
+	if i.cfg.Spool != nil {
+		for _, se := range i.cfg.Spool.takePending() {
+			b, _ := json.Marshal(se.event)
+			add(queuedEvent{event: se.event, size: len(b), record: se.record})
+		}
+	}
+
+	for {
+		select {
+		case qe, ok := <-i.queue:
+			if !ok {
+				flush()
+				return
+			}
+			add(qe)
+		case now := <-ticker.C:
+			for _, qe := range i.takeRetries(now) {
+				add(qe)
+			}
+			flush()
+		}
+	}
+}
+
+func (i *EventIngester) send() {
+	defer i.wg.Done()
+
+	for batch := range i.batches {
+		i.sendBatch(batch)
+	}
+}
+
+// sendBatch sends the batch, reporting every event that failed. When the
+// batch is rejected because some of its events are invalid, the invalid
+// events are reported and the valid ones are sent again, until the batch is
+// accepted or fails as a whole.
+//
+// Events are acknowledged in the spool once they are confirmed or rejected
+// for good. Events that failed for a transient reason stay in the spool.
+func (i *EventIngester) sendBatch(batch []queuedEvent) {
+	if err := i.ctx.Err(); err != nil {
+		i.fail(batch, err)
+		return
+	}
+
+	events := make([]*EventInput, len(batch))
+	for idx, qe := range batch {
+		events[idx] = qe.event
+	}
+
+	_, err := i.client.BatchEvents(i.ctx, &events)
+	if err == nil {
+		i.ack(batch)
+		i.resetRetryDelay()
+		return
+	}
+
+	accepted, rejected := SplitBatchEvents(events, err)
+	if len(accepted) == 0 || len(rejected) == 0 || len(rejected[0].Violations) == 0 {
+		i.fail(batch, err)
+		return
+	}
+
+	var httpErr *HTTPError
+	errors.As(err, &httpErr)
+
+	isRejected := make(map[int]bool, len(rejected))
+	for _, r := range rejected {
+		isRejected[r.Index] = true
+		i.cfg.OnError(r.Event, &HTTPError{
+			HTTPStatusCode: httpErr.HTTPStatusCode,
+			Message:        httpErr.Message,
+			ErrorCode:      httpErr.ErrorCode,
+			ErrorDetail:    ErrorDetail{0: httpErr.ErrorDetail[r.Index]},
+			Method:         httpErr.Method,
+			URL:            httpErr.URL,
+			RequestID:      httpErr.RequestID,
+		})
+	}
+
+	var valid, invalid []queuedEvent
+	for idx, qe := range batch {
+		if isRejected[idx] {
+			invalid = append(invalid, qe)
+		} else {
+			valid = append(valid, qe)
+		}
+	}
+
+	i.ack(invalid)
+	// Every round drops at least one event, so the resends end.
+	i.sendBatch(valid)
+}
+
+// fail handles the events of a batch that failed. Spooled events that failed
+// for a transient reason are kept, and sent again unless the ingester is
+// closing. Other events are dropped, reported to OnError, and acknowledged.
+func (i *EventIngester) fail(batch []queuedEvent, err error) {
+	if i.cfg.Spool != nil && !isPermanentEventError(err) {
+		if i.ctx.Err() == nil {
+			i.retry(batch)
+		}
+		return
+	}
+
+	for _, qe := range batch {
+		i.cfg.OnError(qe.event, err)
+	}
+	i.ack(batch)
+}
+
+// retry schedules the events to be sent again after the backoff delay.
+func (i *EventIngester) retry(batch []queuedEvent) {
+	i.retryMu.Lock()
+	defer i.retryMu.Unlock()
+
+	if i.retryDelay == 0 {
+		i.retryDelay = i.cfg.FlushInterval
+	} else {
+		i.retryDelay = min(2*i.retryDelay, maxEventRetryDelay)
+	}
+	i.retries = append(i.retries, batch...)
+	i.retryAt = time.Now().Add(i.retryDelay)
+}
+
+// takeRetries returns the events to send again, once their delay is over.
+func (i *EventIngester) takeRetries(now time.Time) []queuedEvent {
+	i.retryMu.Lock()
+	defer i.retryMu.Unlock()
+
+	if len(i.retries) == 0 || now.Before(i.retryAt) {
+		return nil
+	}
+	retries := i.retries
+	i.retries = nil
+	return retries
+}
+
+func (i *EventIngester) resetRetryDelay() {
+	i.retryMu.Lock()
+	defer i.retryMu.Unlock()
+
+	i.retryDelay = 0
+}
+
+func (i *EventIngester) ack(batch []queuedEvent) {
+	if i.cfg.Spool == nil || len(batch) == 0 {
+		return
+	}
+
+	records := make([]spoolRecord, len(batch))
+	for idx, qe := range batch {
+		records[idx] = qe.record
+	}
+	// A failed acknowledgement only means the events are sent again when the
+	// spool is replayed, where the transaction ID deduplicates them.
+	_ = i.cfg.Spool.ack(records...)
+}
+
+// isPermanentEventError reports whether resending the events cannot succeed.
+// Only malformed and invalid events are rejected for good: other errors,
+// such as an unauthorized key or a misconfigured URL, can be fixed without
+// losing the events.
+func isPermanentEventError(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	switch httpErr.HTTPStatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
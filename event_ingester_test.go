@@ -0,0 +1,253 @@
+package lago
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]*EventInput
+	respond func(batch []*EventInput) *http.Response
+}
+
+func (r *batchRecorder) Do(req *http.Request) (*http.Response, error) {
+	b, _ := io.ReadAll(req.Body)
+
+	var params struct {
+		Events []*EventInput `json:"events"`
+	}
+	if err := json.Unmarshal(b, &params); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.batches = append(r.batches, params.Events)
+	r.mu.Unlock()
+
+	if r.respond != nil {
+		return r.respond(params.Events), nil
+	}
+	return newTestResponse(http.StatusOK, `{"events":[]}`), nil
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sizes []int
+	for _, b := range r.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func TestEventIngester_Batches(t *testing.T) {
+	rec := &batchRecorder{}
+	c := newTestClient(t, rec, nil)
+
+	ingester, err := c.NewEventIngester(EventIngesterConfig{
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewEventIngester() = %v", err)
+	}
+
+	for range 25 {
+		if err := ingester.Enqueue(&EventInput{Code: "api_calls"}); err != nil {
+			t.Fatalf("Enqueue() = %v", err)
+		}
+	}
+
+	if err := ingester.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	if got, want := rec.sizes(), []int{10, 10, 5}; !slices.Equal(got, want) {
+		t.Errorf("batch sizes = %v, want %v", got, want)
+	}
+	for _, batch := range rec.batches {
+		for _, event := range batch {
+			if event.TransactionID == "" {
+				t.Error("TransactionID is empty, want a generated one")
+			}
+		}
+	}
+
+	if err := ingester.Enqueue(&EventInput{}); !errors.Is(err, ErrEventIngesterClosed) {
+		t.Errorf("Enqueue() after Close = %v, want %v", err, ErrEventIngesterClosed)
+	}
+}
+
+func TestEventIngester_MaxBatchBytes(t *testing.T) {
+	rec := &batchRecorder{}
+	c := newTestClient(t, rec, nil)
+
+	event, _ := json.Marshal(&EventInput{TransactionID: "tx-00", Code: "api_calls"})
+
+	ingester, err := c.NewEventIngester(EventIngesterConfig{
+		MaxBatchBytes: 3 * len(event),
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewEventIngester() = %v", err)
+	}
+
+	for range 7 {
+		if err := ingester.Enqueue(&EventInput{TransactionID: "tx-00", Code: "api_calls"}); err != nil {
+			t.Fatalf("Enqueue() = %v", err)
+		}
+	}
+
+	if err := ingester.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	if got, want := rec.sizes(), []int{3, 3, 1}; !slices.Equal(got, want) {
+		t.Errorf("batch sizes = %v, want %v", got, want)
+	}
+}
+
+func TestEventIngester_FlushInterval(t *testing.T) {
+	rec := &batchRecorder{}
+	c := newTestClient(t, rec, nil)
+
+	ingester, err := c.NewEventIngester(EventIngesterConfig{
+		FlushInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewEventIngester() = %v", err)
+	}
+	defer ingester.Close(context.Background())
+
+	if err := ingester.Enqueue(&EventInput{Code: "api_calls"}); err != nil {
+		t.Fatalf("Enqueue() = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(rec.sizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("batch was not flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEventIngester_PerEventErrors(t *testing.T) {
+	rec := &batchRecorder{
+		respond: func(batch []*EventInput) *http.Response {
+			// The API reports the first invalid event only.
+			for idx, event := range batch {
+				if strings.HasPrefix(event.TransactionID, "invalid") {
+					return newTestResponse(http.StatusUnprocessableEntity, fmt.Sprintf(`{
+  "status": 422,
+  "error": "Unprocessable Entity",
+  "code": "validation_errors",
+  "error_details": {"%d": {"transaction_id": ["value_already_exist"]}}
+}`, idx))
+				}
+			}
+			return newTestResponse(http.StatusOK, `{"events":[]}`)
+		},
+	}
+	c := newTestClient(t, rec, nil)
+
+	var (
+		mu     sync.Mutex
+		failed []string
+	)
+	ingester, err := c.NewEventIngester(EventIngesterConfig{
+		FlushInterval: time.Hour,
+		OnError: func(event *EventInput, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed = append(failed, event.TransactionID)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewEventIngester() = %v", err)
+	}
+
+	for _, id := range []string{"a", "invalid-1", "b", "invalid-2"} {
+		if err := ingester.Enqueue(&EventInput{TransactionID: id}); err != nil {
+			t.Fatalf("Enqueue() = %v", err)
+		}
+	}
+
+	if err := ingester.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	if want := []string{"invalid-1", "invalid-2"}; !slices.Equal(failed, want) {
+		t.Errorf("failed = %v, want %v", failed, want)
+	}
+	if got, want := rec.sizes(), []int{4, 3, 2}; !slices.Equal(got, want) {
+		t.Errorf("batch sizes = %v, want %v", got, want)
+	}
+}
+
+func TestEventIngester_QueueFull(t *testing.T) {
+	block := make(chan struct{})
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		<-block
+		return newTestResponse(http.StatusOK, `{"events":[]}`), nil
+	})
+	c := newTestClient(t, client, nil)
+
+	ingester, err := c.NewEventIngester(EventIngesterConfig{
+		BatchSize: 1,
+		QueueSize: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewEventIngester() = %v", err)
+	}
+
+	var gotErr error
+	for range 10 {
+		if gotErr = ingester.Enqueue(&EventInput{}); gotErr != nil {
+			break
+		}
+	}
+	if !errors.Is(gotErr, ErrEventQueueFull) {
+		t.Errorf("Enqueue() = %v, want %v", gotErr, ErrEventQueueFull)
+	}
+
+	close(block)
+	if err := ingester.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+}
+
+func TestIsPermanentEventError(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"bad request":          {err: &HTTPError{HTTPStatusCode: http.StatusBadRequest}, want: true},
+		"unprocessable entity": {err: &HTTPError{HTTPStatusCode: http.StatusUnprocessableEntity}, want: true},
+		"unauthorized":         {err: &HTTPError{HTTPStatusCode: http.StatusUnauthorized}},
+		"forbidden":            {err: &HTTPError{HTTPStatusCode: http.StatusForbidden}},
+		"not found":            {err: &HTTPError{HTTPStatusCode: http.StatusNotFound}},
+		"too many requests":    {err: &HTTPError{HTTPStatusCode: http.StatusTooManyRequests}},
+		"server error":         {err: &HTTPError{HTTPStatusCode: http.StatusServiceUnavailable}},
+		"transport error":      {err: errors.New("connection refused")},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := isPermanentEventError(tt.err); got != tt.want {
+				t.Errorf("isPermanentEventError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package lago
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SpoolSyncPolicy string
+
+const (
+	// SpoolSyncAlways syncs the segment to disk after every event.
+	SpoolSyncAlways SpoolSyncPolicy = "always"
+	// SpoolSyncInterval syncs the segment to disk every SyncInterval.
+	SpoolSyncInterval SpoolSyncPolicy = "interval"
+	// SpoolSyncNever leaves syncing to the operating system.
+	SpoolSyncNever SpoolSyncPolicy = "never"
+)
+
+const (
+	spoolSegmentExt = ".log"
+	spoolAckExt     = ".ack"
+)
+
+// EventSpoolConfig configures an EventSpool. Zero values are replaced with
+// the defaults documented on each field.
+type EventSpoolConfig struct {
+	// Dir is the directory holding the spool segments. It is created if it
+	// does not exist.
+	Dir string
+	// SegmentSize is the size in bytes after which a new segment is started.
+	// It defaults to 16 MiB.
+	SegmentSize int64
+	// Sync is the policy used to sync segments to disk.
+	// It defaults to SpoolSyncAlways.
+	Sync SpoolSyncPolicy
+	// SyncInterval is the sync period of the SpoolSyncInterval policy.
+	// It defaults to 1 second.
+	SyncInterval time.Duration
+}
+
+func (c *EventSpoolConfig) Validate() error {
+	if c.Dir == "" {
+		return errors.New("Dir is empty")
+	}
+	if c.SegmentSize < 0 {
+		return errors.New("SegmentSize must not be negative")
+	}
+	switch c.Sync {
+	case "", SpoolSyncAlways, SpoolSyncInterval, SpoolSyncNever:
+	default:
+		return fmt.Errorf("unknown Sync policy %q", c.Sync)
+	}
+	if c.SyncInterval < 0 {
+		return errors.New("SyncInterval must not be negative")
+	}
+	return nil
+}
+
+// EventSpool is an append-only write-ahead log of events, stored in
+// rotating segment files. Events are appended before they are sent and
+// acknowledged once the batch endpoint confirms them. A segment is removed
+// once all of its events are acknowledged. Events not acknowledged when the
+// process stops are replayed by the next EventIngester using the spool.
+//
+// Each segment <seq>.log holds one JSON encoded event per line, and the
+// companion <seq>.ack file lists the acknowledged line numbers.
+type EventSpool struct {
+	cfg EventSpoolConfig
+
+	mu       sync.Mutex
+	closed   bool
+	active   *spoolSegment
+	segments []*spoolSegment
+	pending  []spooledEvent
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type spoolSegment struct {
+	seq      int
+	records  int
+	acked    int
+	size     int64
+	file     *os.File
+	ackFile  *os.File
+	finished bool
+}
+
+type spoolRecord struct {
+	segment *spoolSegment
+	index   int
+}
+
+type spooledEvent struct {
+	event  *EventInput
+	record spoolRecord
+}
+
+// OpenEventSpool opens the spool stored in cfg.Dir, loading the events that
+// were not acknowledged so they can be replayed.
+func OpenEventSpool(cfg EventSpoolConfig) (*EventSpool, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.SegmentSize == 0 {
+		cfg.SegmentSize = 16 << 20
+	}
+	if cfg.Sync == "" {
+		cfg.Sync = SpoolSyncAlways
+	}
+	if cfg.SyncInterval == 0 {
+		cfg.SyncInterval = time.Second
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	s := &EventSpool{cfg: cfg}
+
+	seqs, err := s.segmentSeqs()
+	if err != nil {
+		return nil, err
+	}
+
+	next := 1
+	for _, seq := range seqs {
+		if err := s.load(seq); err != nil {
+			s.closeFiles()
+			return nil, err
+		}
+		next = seq + 1
+	}
+
+	if err := s.rotate(next); err != nil {
+		s.closeFiles()
+		return nil, err
+	}
+
+	if cfg.Sync == SpoolSyncInterval {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.syncLoop()
+	}
+
+	return s, nil
+}
+
+func (s *EventSpool) segmentPath(seq int, ext string) string {
+	return filepath.Join(s.cfg.Dir, fmt.Sprintf("%012d%s", seq, ext))
+}
+
+func (s *EventSpool) segmentSeqs() ([]int, error) {
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %w", err)
+	}
+
+	var seqs []int
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), spoolSegmentExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		seq, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	slices.Sort(seqs)
+	return seqs, nil
+}
+
+// load reads a segment left by a previous process and queues its
+// unacknowledged events for replay.
+func (s *EventSpool) load(seq int) error {
+	acked := make(map[int]bool)
+	if b, err := os.ReadFile(s.segmentPath(seq, spoolAckExt)); err == nil {
+		for _, line := range strings.Fields(string(b)) {
+			if idx, err := strconv.Atoi(line); err == nil {
+				acked[idx] = true
+			}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read spool acknowledgements: %w", err)
+	}
+
+	b, err := os.ReadFile(s.segmentPath(seq, spoolSegmentExt))
+	if err != nil {
+		return fmt.Errorf("failed to read spool segment: %w", err)
+	}
+
+	seg := &spoolSegment{seq: seq, finished: true}
+
+	var pending []spooledEvent
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(nil, len(b)+1)
+	for idx := 0; sc.Scan(); idx++ {
+		seg.records++
+		if acked[idx] {
+			seg.acked++
+			continue
+		}
+
+		var event EventInput
+		if err := json.Unmarshal(sc.Bytes(), &event); err != nil {
+			// A torn write from a crash, there is nothing to replay.
+			seg.acked++
+			continue
+		}
+		pending = append(pending, spooledEvent{
+			event:  &event,
+			record: spoolRecord{segment: seg, index: idx},
+		})
+	}
+
+	if seg.acked == seg.records {
+		return s.remove(seg)
+	}
+
+	seg.ackFile, err = os.OpenFile(s.segmentPath(seq, spoolAckExt), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open spool acknowledgements: %w", err)
+	}
+
+	s.segments = append(s.segments, seg)
+	s.pending = append(s.pending, pending...)
+	return nil
+}
+
+// rotate finishes the active segment and starts a new one.
+// It must be called with s.mu held, or before the spool is shared.
+func (s *EventSpool) rotate(seq int) error {
+	if s.active != nil {
+		if err := s.active.file.Sync(); err != nil {
+			return err
+		}
+		if err := s.active.file.Close(); err != nil {
+			return err
+		}
+		s.active.file = nil
+		s.active.finished = true
+		if s.active.acked == s.active.records {
+			if err := s.remove(s.active); err != nil {
+				return err
+			}
+		}
+	}
+
+	file, err := os.OpenFile(s.segmentPath(seq, spoolSegmentExt), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create spool segment: %w", err)
+	}
+	ackFile, err := os.OpenFile(s.segmentPath(seq, spoolAckExt), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to create spool acknowledgements: %w", err)
+	}
+
+	s.active = &spoolSegment{seq: seq, file: file, ackFile: ackFile}
+	s.segments = append(s.segments, s.active)
+	return nil
+}
+
+func (s *EventSpool) remove(seg *spoolSegment) error {
+	if seg.ackFile != nil {
+		seg.ackFile.Close()
+		seg.ackFile = nil
+	}
+	s.segments = slices.DeleteFunc(s.segments, func(other *spoolSegment) bool {
+		return other == seg
+	})
+
+	if err := os.Remove(s.segmentPath(seg.seq, spoolSegmentExt)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove spool segment: %w", err)
+	}
+	if err := os.Remove(s.segmentPath(seg.seq, spoolAckExt)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove spool acknowledgements: %w", err)
+	}
+	return nil
+}
+
+// append writes the encoded event to the active segment.
+func (s *EventSpool) append(encoded []byte) (spoolRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return spoolRecord{}, errors.New("event spool is closed")
+	}
+
+	if s.active.size > 0 && s.active.size+int64(len(encoded))+1 > s.cfg.SegmentSize {
+		if err := s.rotate(s.active.seq + 1); err != nil {
+			return spoolRecord{}, err
+		}
+	}
+
+	seg := s.active
+	line := append(slices.Clip(encoded), '\n')
+	if _, err := seg.file.Write(line); err != nil {
+		return spoolRecord{}, fmt.Errorf("failed to write spool segment: %w", err)
+	}
+	if s.cfg.Sync == SpoolSyncAlways {
+		if err := seg.file.Sync(); err != nil {
+			return spoolRecord{}, fmt.Errorf("failed to sync spool segment: %w", err)
+		}
+	}
+
+	rec := spoolRecord{segment: seg, index: seg.records}
+	seg.records++
+	seg.size += int64(len(line))
+	return rec, nil
+}
+
+// ack marks the records as confirmed, removing the segments whose records
+// are all confirmed.
+func (s *EventSpool) ack(records ...spoolRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errors.New("event spool is closed")
+	}
+
+	var buf bytes.Buffer
+	for i, rec := range records {
+		seg := rec.segment
+		buf.WriteString(strconv.Itoa(rec.index))
+		buf.WriteByte('\n')
+		seg.acked++
+
+		if i+1 < len(records) && records[i+1].segment == seg {
+			continue
+		}
+
+		if seg.finished && seg.acked == seg.records {
+			if err := s.remove(seg); err != nil {
+				return err
+			}
+		} else if _, err := seg.ackFile.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write spool acknowledgements: %w", err)
+		}
+		buf.Reset()
+	}
+
+	return nil
+}
+
+// takePending returns the events left by a previous process, once.
+func (s *EventSpool) takePending() []spooledEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pending
+	s.pending = nil
+	return pending
+}
+
+func (s *EventSpool) syncLoop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			if s.active != nil && s.active.file != nil {
+				s.active.file.Sync()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close syncs and closes the spool. Events that were not acknowledged stay
+// on disk and are replayed when the spool is opened again.
+func (s *EventSpool) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeFiles()
+}
+
+func (s *EventSpool) closeFiles() error {
+	var errs []error
+	for _, seg := range s.segments {
+		if seg.file != nil {
+			errs = append(errs, seg.file.Sync(), seg.file.Close())
+			seg.file = nil
+		}
+		if seg.ackFile != nil {
+			errs = append(errs, seg.ackFile.Close())
+			seg.ackFile = nil
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,280 @@
+package lago
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func openTestSpool(t *testing.T, dir string, segmentSize int64) *EventSpool {
+	t.Helper()
+
+	spool, err := OpenEventSpool(EventSpoolConfig{
+		Dir:         dir,
+		SegmentSize: segmentSize,
+		Sync:        SpoolSyncNever,
+	})
+	if err != nil {
+		t.Fatalf("OpenEventSpool() = %v", err)
+	}
+	return spool
+}
+
+func spoolFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() = %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestEventSpool_ReplayAfterFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	unavailable := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		return newTestResponse(http.StatusServiceUnavailable, `{"status":503}`), nil
+	})
+
+	spool := openTestSpool(t, dir, 0)
+	ingester, err := newTestClient(t, unavailable, nil).NewEventIngester(EventIngesterConfig{
+		FlushInterval: time.Hour,
+		Spool:         spool,
+	})
+	if err != nil {
+		t.Fatalf("NewEventIngester() = %v", err)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if err := ingester.Enqueue(&EventInput{TransactionID: id}); err != nil {
+			t.Fatalf("Enqueue() = %v", err)
+		}
+	}
+	if err := ingester.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := spool.Close(); err != nil {
+		t.Fatalf("spool.Close() = %v", err)
+	}
+
+	rec := &batchRecorder{}
+	spool = openTestSpool(t, dir, 0)
+	ingester, err = newTestClient(t, rec, nil).NewEventIngester(EventIngesterConfig{
+		FlushInterval: time.Hour,
+		Spool:         spool,
+	})
+	if err != nil {
+		t.Fatalf("NewEventIngester() = %v", err)
+	}
+	if err := ingester.Enqueue(&EventInput{TransactionID: "d"}); err != nil {
+		t.Fatalf("Enqueue() = %v", err)
+	}
+	if err := ingester.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	var got []string
+	for _, batch := range rec.batches {
+		for _, event := range batch {
+			got = append(got, event.TransactionID)
+		}
+	}
+	if want := []string{"a", "b", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("sent = %v, want %v", got, want)
+	}
+
+	if err := spool.Close(); err != nil {
+		t.Fatalf("spool.Close() = %v", err)
+	}
+
+	// Only the empty active segment of the second run is left.
+	if got := spoolFiles(t, dir); len(got) != 2 {
+		t.Errorf("spool files = %v, want the active segment only", got)
+	}
+	spool = openTestSpool(t, dir, 0)
+	defer spool.Close()
+	if pending := spool.takePending(); len(pending) != 0 {
+		t.Errorf("pending = %d, want 0", len(pending))
+	}
+}
+
+func TestEventSpool_RetryAfterRecovery(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		calls    int
+		sent     []string
+		reported []string
+	)
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if calls <= 2 {
+			return newTestResponse(http.StatusServiceUnavailable, `{"status":503}`), nil
+		}
+
+		var params struct {
+			Events []*EventInput `json:"events"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+			return nil, err
+		}
+		for _, event := range params.Events {
+			sent = append(sent, event.TransactionID)
+		}
+		return newTestResponse(http.StatusOK, `{"events":[]}`), nil
+	})
+
+	dir := t.TempDir()
+	spool := openTestSpool(t, dir, 0)
+	defer spool.Close()
+
+	ingester, err := newTestClient(t, client, nil).NewEventIngester(EventIngesterConfig{
+		FlushInterval: 5 * time.Millisecond,
+		Spool:         spool,
+		OnError: func(event *EventInput, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, event.TransactionID)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewEventIngester() = %v", err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if err := ingester.Enqueue(&EventInput{TransactionID: id}); err != nil {
+			t.Fatalf("Enqueue() = %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(sent)
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("events were not sent again after the server recovered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := ingester.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"a", "b"}; !slices.Equal(sent, want) {
+		t.Errorf("sent = %v, want %v", sent, want)
+	}
+	// The events were kept in the spool until delivered, so none was lost.
+	if len(reported) != 0 {
+		t.Errorf("OnError called for %v, want no event", reported)
+	}
+	if pending := spool.takePending(); len(pending) != 0 {
+		t.Errorf("pending = %d, want 0", len(pending))
+	}
+	// The acknowledged events leave the active segment empty.
+	if spool.active.acked != spool.active.records {
+		t.Errorf("acked %d of %d records", spool.active.acked, spool.active.records)
+	}
+}
+
+func TestEventSpool_RotateAndAck(t *testing.T) {
+	dir := t.TempDir()
+	spool := openTestSpool(t, dir, 64)
+	defer spool.Close()
+
+	var records []spoolRecord
+	for range 10 {
+		rec, err := spool.append([]byte(`{"transaction_id":"0123456789"}`))
+		if err != nil {
+			t.Fatalf("append() = %v", err)
+		}
+		records = append(records, rec)
+	}
+
+	if got := spoolFiles(t, dir); len(got) != 10 {
+		t.Fatalf("spool files = %v, want 5 segments", got)
+	}
+
+	if err := spool.ack(records[:8]...); err != nil {
+		t.Fatalf("ack() = %v", err)
+	}
+
+	// The segment holding the last two records is the active one.
+	if got := spoolFiles(t, dir); len(got) != 2 {
+		t.Errorf("spool files = %v, want the active segment only", got)
+	}
+}
+
+func TestEventSpool_TornWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	spool := openTestSpool(t, dir, 0)
+	if _, err := spool.append([]byte(`{"transaction_id":"a"}`)); err != nil {
+		t.Fatalf("append() = %v", err)
+	}
+	if err := spool.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, "000000000001.log"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("OpenFile() = %v", err)
+	}
+	f.WriteString(`{"transaction_id":"b"`)
+	f.Close()
+
+	spool = openTestSpool(t, dir, 0)
+	defer spool.Close()
+
+	pending := spool.takePending()
+	if len(pending) != 1 || pending[0].event.TransactionID != "a" {
+		t.Errorf("pending = %+v, want the complete event only", pending)
+	}
+}
+
+func TestEventSpoolConfig_Validate(t *testing.T) {
+	tt := map[string]struct {
+		c       *EventSpoolConfig
+		wantErr bool
+	}{
+		"valid": {
+			c:       &EventSpoolConfig{Dir: "spool", Sync: SpoolSyncInterval},
+			wantErr: false,
+		},
+		"empty Dir": {
+			c:       &EventSpoolConfig{},
+			wantErr: true,
+		},
+		"unknown Sync": {
+			c:       &EventSpoolConfig{Dir: "spool", Sync: "sometimes"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			gotErr := tc.c.Validate()
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", gotErr, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package lago
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestSplitBatchEvents(t *testing.T) {
+	batch := []*EventInput{
+		{TransactionID: "a"},
+		{TransactionID: "b"},
+		{TransactionID: "c"},
+	}
+
+	validationErr := &HTTPError{
+		HTTPStatusCode: http.StatusUnprocessableEntity,
+		ErrorCode:      "validation_errors",
+		ErrorDetail: ErrorDetail{
+			1: {"transaction_id": {"value_already_exist"}},
+		},
+		IndexedErrorDetail: true,
+	}
+	batchErr := &HTTPError{
+		HTTPStatusCode: http.StatusUnprocessableEntity,
+		ErrorCode:      "validation_errors",
+		ErrorDetail: ErrorDetail{
+			0: {"events": {"too_many_events"}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		err          error
+		wantAccepted []string
+		wantRejected []string
+	}{
+		{
+			name:         "no error",
+			wantAccepted: []string{"a", "b", "c"},
+		},
+		{
+			name:         "validation error",
+			err:          validationErr,
+			wantAccepted: []string{"a", "c"},
+			wantRejected: []string{"b"},
+		},
+		{
+			name:         "batch validation error",
+			err:          batchErr,
+			wantRejected: []string{"a", "b", "c"},
+		},
+		{
+			name:         "other error",
+			err:          errors.New("connection reset"),
+			wantRejected: []string{"a", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted, rejected := SplitBatchEvents(batch, tt.err)
+
+			var gotAccepted, gotRejected []string
+			for _, event := range accepted {
+				gotAccepted = append(gotAccepted, event.TransactionID)
+			}
+			for _, r := range rejected {
+				if batch[r.Index] != r.Event {
+					t.Errorf("rejected event %q is not at index %d", r.Event.TransactionID, r.Index)
+				}
+				gotRejected = append(gotRejected, r.Event.TransactionID)
+			}
+
+			if !slices.Equal(gotAccepted, tt.wantAccepted) {
+				t.Errorf("accepted = %v, want %v", gotAccepted, tt.wantAccepted)
+			}
+			if !slices.Equal(gotRejected, tt.wantRejected) {
+				t.Errorf("rejected = %v, want %v", gotRejected, tt.wantRejected)
+			}
+		})
+	}
+
+	_, rejected := SplitBatchEvents(batch, validationErr)
+	if v := rejected[0].Violations; len(v) != 1 || v[0].Field != "transaction_id" {
+		t.Errorf("Violations = %+v, want the transaction_id violation", v)
+	}
+}
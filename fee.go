@@ -214,7 +214,7 @@ type Fee struct {
 }
 
 func (c *Client) GetFee(ctx context.Context, feeID string) (*Fee, error) {
-	u := c.url("fees/"+feeID, nil)
+	u := c.url("GetFee", "fees/{lago_id}", nil, feeID)
 	result, err := get[feeResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -224,7 +224,7 @@ func (c *Client) GetFee(ctx context.Context, feeID string) (*Fee, error) {
 }
 
 func (c *Client) UpdateFee(ctx context.Context, feeInput *FeeUpdateInput) (*Fee, error) {
-	u := c.url("fees/"+feeInput.LagoID.String(), nil)
+	u := c.url("UpdateFee", "fees/{lago_id}", nil, feeInput.LagoID.String())
 	result, err := put[feeUpdateParams, feeResult](
 		ctx,
 		c,
@@ -239,12 +239,12 @@ func (c *Client) UpdateFee(ctx context.Context, feeInput *FeeUpdateInput) (*Fee,
 }
 
 func (c *Client) ListFees(ctx context.Context, feeListInput *FeeListInput) (*FeeList, error) {
-	u := c.url("fees", feeListInput.query())
+	u := c.url("ListFees", "fees", feeListInput.query())
 	return get[FeeList](ctx, c, u)
 }
 
 func (c *Client) DeleteFee(ctx context.Context, feeID string) (*Fee, error) {
-	u := c.url("fees/"+feeID, nil)
+	u := c.url("DeleteFee", "fees/{lago_id}", nil, feeID)
 	result, err := delete[feeResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -42,6 +42,6 @@ type GrossRevenue struct {
 }
 
 func (c *Client) ListGrossRevenues(ctx context.Context, GrossRevenueListInput *GrossRevenueListInput) (*GrossRevenueList, error) {
-	u := c.url("analytics/gross_revenue", GrossRevenueListInput.query())
+	u := c.url("ListGrossRevenues", "analytics/gross_revenue", GrossRevenueListInput.query())
 	return get[GrossRevenueList](ctx, c, u)
 }
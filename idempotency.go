@@ -0,0 +1,45 @@
+package lago
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader is the header carrying the idempotency key of a
+// mutating request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a context that makes the POST request issued
+// with it carry the given idempotency key. The same key is sent on every
+// retry of the request, so resending a call with the same key after a
+// timeout cannot create the same resource twice.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key stored in the
+// context by WithIdempotencyKey.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok && key != ""
+}
+
+// idempotencyKey returns the key to send with the request. When the context
+// does not carry one, a random key is generated if the client is configured
+// to do so.
+func (c *Client) idempotencyKey(ctx context.Context, method string) string {
+	if method != http.MethodPost {
+		return ""
+	}
+	if key, ok := IdempotencyKeyFromContext(ctx); ok {
+		return key
+	}
+	if c.idempotencyKeys {
+		return uuid.NewString()
+	}
+	return ""
+}
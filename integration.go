@@ -0,0 +1,110 @@
+package lago
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IntegrationList struct {
+	Integrations []*Integration `json:"integrations,omitempty"`
+	Meta         Metadata       `json:"meta,omitempty"`
+}
+
+type IntegrationListInput struct {
+	PerPage int               `json:"per_page,omitempty,string"`
+	Page    int               `json:"page,omitempty,string"`
+	Types   []IntegrationType `json:"types,omitempty"`
+}
+
+func (i *IntegrationListInput) query() url.Values {
+	q := make(url.Values)
+
+	if i.PerPage > 0 {
+		q.Add("per_page", strconv.Itoa(i.PerPage))
+	}
+	if i.Page > 0 {
+		q.Add("page", strconv.Itoa(i.Page))
+	}
+	for _, t := range i.Types {
+		q.Add("types[]", string(t))
+	}
+
+	return q
+}
+
+// Integration is an integration of the organization, such as an accounting
+// or tax provider connection.
+type Integration struct {
+	LagoID    uuid.UUID       `json:"lago_id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Code      string          `json:"code,omitempty"`
+	Type      IntegrationType `json:"type,omitempty"`
+	CreatedAt time.Time       `json:"created_at,omitempty"`
+}
+
+// MarshalJSON omits the zero LagoID, which would otherwise be sent as the ID
+// of an existing link to update.
+func (ic IntegrationCustomer) MarshalJSON() ([]byte, error) {
+	type integrationCustomer IntegrationCustomer
+	v := struct {
+		LagoID *uuid.UUID `json:"id,omitempty"`
+		integrationCustomer
+	}{integrationCustomer: integrationCustomer(ic)}
+	if ic.LagoID != uuid.Nil {
+		v.LagoID = &ic.LagoID
+	}
+	return json.Marshal(v)
+}
+
+// Synced reports whether the customer was created in the integration.
+// The external customer ID is only known once the sync has succeeded.
+func (r *IntegrationCustomersResponse) Synced() bool {
+	return r.ExternalCustomerId != ""
+}
+
+func (c *Client) ListIntegrations(ctx context.Context, integrationListInput *IntegrationListInput) (*IntegrationList, error) {
+	u := c.url("ListIntegrations", "integrations", integrationListInput.query())
+	return get[IntegrationList](ctx, c, u)
+}
+
+// SyncCustomerIntegration links the customer to the integration, creating
+// the customer in the integration when SyncWithProvider is set, and returns
+// the resulting link. The sync is asynchronous, see
+// GetCustomerIntegration to follow it.
+func (c *Client) SyncCustomerIntegration(ctx context.Context, externalCustomerID string, integrationCustomer *IntegrationCustomer) (*IntegrationCustomersResponse, error) {
+	customer, err := c.CreateCustomer(ctx, &CustomerInput{
+		ExternalID:           externalCustomerID,
+		IntegrationCustomers: []*IntegrationCustomer{integrationCustomer},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return findIntegrationCustomer(customer, integrationCustomer.IntegrationCode)
+}
+
+// GetCustomerIntegration returns the link of the customer to the
+// integration, including its sync status.
+func (c *Client) GetCustomerIntegration(ctx context.Context, externalCustomerID string, integrationCode string) (*IntegrationCustomersResponse, error) {
+	customer, err := c.GetCustomer(ctx, externalCustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return findIntegrationCustomer(customer, integrationCode)
+}
+
+func findIntegrationCustomer(customer *Customer, integrationCode string) (*IntegrationCustomersResponse, error) {
+	for _, ic := range customer.IntegrationCustomers {
+		if ic.IntegrationCode == integrationCode {
+			return ic, nil
+		}
+	}
+	return nil, fmt.Errorf("customer %q is not linked to integration %q", customer.ExternalID, integrationCode)
+}
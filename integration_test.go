@@ -0,0 +1,152 @@
+package lago
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestClient_Integrations(t *testing.T) {
+	ctx := context.Background()
+	customer := `{"customer":{"external_id":"cus","integration_customers":[
+		{"integration_code":"anrok-prod","type":"anrok"},
+		{"integration_code":"netsuite-prod","type":"netsuite","external_customer_id":"ns-1"}
+	]}}`
+
+	tests := map[string]requestTest{
+		"list": {
+			call: func(c *Client) error {
+				list, err := c.ListIntegrations(ctx, &IntegrationListInput{
+					Types: []IntegrationType{IntegrationNetsuite, IntegrationXero},
+				})
+				if err == nil && (len(list.Integrations) != 1 || list.Integrations[0].Type != IntegrationNetsuite) {
+					t.Errorf("ListIntegrations() = %+v", list.Integrations)
+				}
+				return err
+			},
+			response:   `{"integrations":[{"code":"netsuite-prod","type":"netsuite"}],"meta":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/integrations?types%5B%5D=netsuite&types%5B%5D=xero",
+		},
+		"sync": {
+			call: func(c *Client) error {
+				ic, err := c.SyncCustomerIntegration(ctx, "cus", &IntegrationCustomer{
+					IntegrationType:  IntegrationAnrok,
+					IntegrationCode:  "anrok-prod",
+					SyncWithProvider: true,
+				})
+				if err == nil && ic.Synced() {
+					t.Error("Synced() = true, want false before the customer is created in the integration")
+				}
+				return err
+			},
+			response:   customer,
+			wantMethod: http.MethodPost,
+			wantURI:    "/api/v1/customers",
+			wantBody:   `{"customer":{"external_id":"cus","billing_configuration":{},"shipping_address":{},"integration_customers":[{"integration_type":"anrok","integration_code":"anrok-prod","sync_with_provider":true}]}}`,
+		},
+		"get": {
+			call: func(c *Client) error {
+				ic, err := c.GetCustomerIntegration(ctx, "cus", "netsuite-prod")
+				if err == nil && !ic.Synced() {
+					t.Error("Synced() = false, want true")
+				}
+				return err
+			},
+			response:   customer,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/customers/cus",
+		},
+		"get unlinked": {
+			call: func(c *Client) error {
+				if _, err := c.GetCustomerIntegration(ctx, "cus", "xero-prod"); err == nil {
+					t.Error("GetCustomerIntegration() of an unlinked integration succeeded")
+				}
+				return nil
+			},
+			response:   customer,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/customers/cus",
+		},
+	}
+
+	runRequestTests(t, tests)
+}
+
+func TestClient_SetCustomerPaymentProvider(t *testing.T) {
+	tests := map[string]struct {
+		input   *CustomerPaymentProviderInput
+		want    map[string]any
+		wantErr bool
+	}{
+		"stripe": {
+			input: &CustomerPaymentProviderInput{
+				PaymentProvider:        PaymentProviderStripe,
+				PaymentProviderCode:    "stripe-eu",
+				SyncWithProvider:       true,
+				ProviderPaymentMethods: []string{"card", "sepa_debit"},
+			},
+			want: map[string]any{
+				"payment_provider":         "stripe",
+				"payment_provider_code":    "stripe-eu",
+				"sync_with_provider":       true,
+				"provider_payment_methods": []any{"card", "sepa_debit"},
+			},
+		},
+		"adyen": {
+			input: &CustomerPaymentProviderInput{PaymentProvider: PaymentProviderAdyen, ProviderCustomerID: "shopper-1"},
+			want:  map[string]any{"payment_provider": "adyen", "provider_customer_id": "shopper-1"},
+		},
+		"gocardless": {
+			input: &CustomerPaymentProviderInput{PaymentProvider: PaymentProviderGocardless, SyncWithProvider: true},
+			want:  map[string]any{"payment_provider": "gocardless", "sync_with_provider": true},
+		},
+		"moneyhash": {
+			input: &CustomerPaymentProviderInput{PaymentProvider: PaymentProviderMoneyhash, ProviderCustomerID: "mh-1"},
+			want:  map[string]any{"payment_provider": "moneyhash", "provider_customer_id": "mh-1"},
+		},
+		"missing provider": {
+			input:   &CustomerPaymentProviderInput{ProviderCustomerID: "cus_1"},
+			wantErr: true,
+		},
+		"payment methods without stripe": {
+			input:   &CustomerPaymentProviderInput{PaymentProvider: PaymentProviderAdyen, ProviderPaymentMethods: []string{"card"}},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got struct {
+				Customer struct {
+					BillingConfiguration map[string]any `json:"billing_configuration"`
+				} `json:"customer"`
+			}
+			client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+					t.Errorf("Decode() = %v", err)
+				}
+				return newTestResponse(http.StatusOK, `{"customer":{"external_id":"cus"}}`), nil
+			})
+			c := newTestClient(t, client, nil)
+
+			_, err := c.SetCustomerPaymentProvider(context.Background(), "cus", tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("SetCustomerPaymentProvider() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetCustomerPaymentProvider() = %v", err)
+			}
+
+			gotJSON, _ := json.Marshal(got.Customer.BillingConfiguration)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("billing_configuration = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package lago
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Operation describes the API call made by a Client method.
+// Interceptors must not modify it.
+type Operation struct {
+	// Name is the name of the Client method, such as "CreateSubscription".
+	Name string
+	// Method is the HTTP method of the request.
+	Method string
+	// PathTemplate is the path of the endpoint relative to ApiV1Path, with
+	// placeholders for its parameters, such as
+	// "customers/{external_customer_id}/invoices".
+	PathTemplate string
+	// Resource is the top-level resource of the endpoint, such as
+	// "customers".
+	Resource string
+	// PathParams are the values of the placeholders of PathTemplate.
+	PathParams map[string]string
+}
+
+// Invoker sends a request and returns its response.
+type Invoker func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps the API calls made by a Client.
+//
+// An interceptor receives every request before it is sent, and calls next to
+// send it. It can change the request, record the response, return a response
+// of its own without calling next, or call next several times. The request
+// body can be read again with req.GetBody, which an interceptor replacing the
+// body must set too.
+//
+// The Invoker ending the chain applies the rate limiting and the retry
+// policy of the client, so an interceptor sees one call per operation. A
+// non-200 response returned by the chain is turned into an *HTTPError.
+//
+//	logCalls := func(op *lago.Operation, req *http.Request, next lago.Invoker) (*http.Response, error) {
+//		start := time.Now()
+//		res, err := next(req)
+//		log.Printf("%s took %s", op.Name, time.Since(start))
+//		return res, err
+//	}
+type Interceptor func(op *Operation, req *http.Request, next Invoker) (*http.Response, error)
+
+type operationContextKey struct{}
+
+// OperationFromContext returns the operation of the request carrying ctx.
+// It lets an HTTPClient tell which operation it sends a request for.
+func OperationFromContext(ctx context.Context) (*Operation, bool) {
+	op, ok := ctx.Value(operationContextKey{}).(*Operation)
+	return op, ok
+}
+
+// intercept sends the request through the interceptors of the client.
+// The first interceptor is the outermost.
+func (c *Client) intercept(op *Operation, req *http.Request) (*http.Response, error) {
+	next := c.invoke
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := c.interceptors[i], next
+		next = func(req *http.Request) (*http.Response, error) {
+			return interceptor(op, req, inner)
+		}
+	}
+	return next(req)
+}
+
+// endpoint is the URL of an operation.
+type endpoint struct {
+	url string
+	op  Operation
+}
+
+// newEndpoint returns the endpoint of the operation. The placeholders of the
+// path template are replaced by the params, in order.
+func newEndpoint(base *url.URL, name, template string, q url.Values, params []string) *endpoint {
+	op := Operation{
+		Name:         name,
+		PathTemplate: template,
+	}
+	op.Resource, _, _ = strings.Cut(template, "/")
+
+	var path strings.Builder
+	rest := template
+	for _, param := range params {
+		before, after, ok := strings.Cut(rest, "{")
+		if !ok {
+			break
+		}
+		key, after, _ := strings.Cut(after, "}")
+		if op.PathParams == nil {
+			op.PathParams = make(map[string]string, len(params))
+		}
+		op.PathParams[key] = param
+
+		path.WriteString(before)
+		path.WriteString(param)
+		rest = after
+	}
+	path.WriteString(rest)
+
+	return &endpoint{
+		url: apiURL(base, path.String(), q),
+		op:  op,
+	}
+}
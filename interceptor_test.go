@@ -0,0 +1,251 @@
+package lago
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestInterceptors_Operation(t *testing.T) {
+	creditNoteID := uuid.MustParse("1a901a90-1a90-1a90-1a90-1a901a901a90")
+
+	tests := map[string]struct {
+		call     func(c *Client) error
+		wantOp   Operation
+		wantPath string
+	}{
+		"get": {
+			call: func(c *Client) error {
+				_, err := c.GetCustomer(context.Background(), "cus_1")
+				return err
+			},
+			wantOp: Operation{
+				Name:         "GetCustomer",
+				Method:       http.MethodGet,
+				PathTemplate: "customers/{external_customer_id}",
+				Resource:     "customers",
+				PathParams:   map[string]string{"external_customer_id": "cus_1"},
+			},
+			wantPath: "/api/v1/customers/cus_1",
+		},
+		"nested": {
+			call: func(c *Client) error {
+				_, err := c.DeleteAppliedCoupon(context.Background(), "cus_1", "ac_1")
+				return err
+			},
+			wantOp: Operation{
+				Name:         "DeleteAppliedCoupon",
+				Method:       http.MethodDelete,
+				PathTemplate: "customers/{external_customer_id}/applied_coupons/{applied_coupon_id}",
+				Resource:     "customers",
+				PathParams:   map[string]string{"external_customer_id": "cus_1", "applied_coupon_id": "ac_1"},
+			},
+			wantPath: "/api/v1/customers/cus_1/applied_coupons/ac_1",
+		},
+		"uuid": {
+			call: func(c *Client) error {
+				_, err := c.GetCreditNote(context.Background(), creditNoteID)
+				return err
+			},
+			wantOp: Operation{
+				Name:         "GetCreditNote",
+				Method:       http.MethodGet,
+				PathTemplate: "credit_notes/{lago_id}",
+				Resource:     "credit_notes",
+				PathParams:   map[string]string{"lago_id": creditNoteID.String()},
+			},
+			wantPath: "/api/v1/credit_notes/" + creditNoteID.String(),
+		},
+		"without params": {
+			call: func(c *Client) error {
+				_, err := c.CreateEvent(context.Background(), &EventInput{TransactionID: "tx_1"})
+				return err
+			},
+			wantOp: Operation{
+				Name:         "CreateEvent",
+				Method:       http.MethodPost,
+				PathTemplate: "events",
+				Resource:     "events",
+			},
+			wantPath: "/api/v1/events",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var fromContext *Operation
+			client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				fromContext, _ = OperationFromContext(req.Context())
+				return newTestResponse(http.StatusNotFound, `{"status":404}`), nil
+			})
+
+			var got *Operation
+			var gotPath string
+			c, err := NewWithOptions("test", WithHTTPClient(client), WithInterceptors(
+				func(op *Operation, req *http.Request, next Invoker) (*http.Response, error) {
+					got, gotPath = op, req.URL.Path
+					return next(req)
+				},
+			))
+			if err != nil {
+				t.Fatalf("NewWithOptions() = %v", err)
+			}
+
+			if err := tt.call(c); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("call = %v, want %v", err, ErrNotFound)
+			}
+
+			if got == nil {
+				t.Fatal("interceptor not called")
+			}
+			if got.Name != tt.wantOp.Name ||
+				got.Method != tt.wantOp.Method ||
+				got.PathTemplate != tt.wantOp.PathTemplate ||
+				got.Resource != tt.wantOp.Resource ||
+				!maps.Equal(got.PathParams, tt.wantOp.PathParams) {
+				t.Errorf("operation = %+v, want %+v", got, tt.wantOp)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("path = %q, want %q", gotPath, tt.wantPath)
+			}
+			if fromContext != got {
+				t.Errorf("OperationFromContext() = %+v, want %+v", fromContext, got)
+			}
+		})
+	}
+}
+
+func TestInterceptors_Chain(t *testing.T) {
+	var calls []string
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		calls = append(calls, "send "+req.Header.Get("X-Tenant"))
+		return newTestResponse(http.StatusOK, `{"customer":{"external_id":"cus"}}`), nil
+	})
+
+	record := func(name string) Interceptor {
+		return func(op *Operation, req *http.Request, next Invoker) (*http.Response, error) {
+			calls = append(calls, name+" before")
+			res, err := next(req)
+			calls = append(calls, name+" after")
+			return res, err
+		}
+	}
+	mutate := func(op *Operation, req *http.Request, next Invoker) (*http.Response, error) {
+		req.Header.Set("X-Tenant", "acme")
+		return next(req)
+	}
+
+	c, err := NewWithOptions("test", WithHTTPClient(client), WithInterceptors(record("outer"), mutate, record("inner")))
+	if err != nil {
+		t.Fatalf("NewWithOptions() = %v", err)
+	}
+
+	if _, err := c.GetCustomer(context.Background(), "cus"); err != nil {
+		t.Fatalf("GetCustomer() = %v", err)
+	}
+
+	want := []string{"outer before", "inner before", "send acme", "inner after", "outer after"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestInterceptors_ShortCircuit(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		t.Error("request sent")
+		return nil, errors.New("unexpected request")
+	})
+
+	tests := map[string]struct {
+		interceptor Interceptor
+		wantErr     error
+		wantStatus  int
+	}{
+		"response": {
+			interceptor: func(op *Operation, req *http.Request, next Invoker) (*http.Response, error) {
+				return newTestResponse(http.StatusOK, `{"customer":{"external_id":"cached"}}`), nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		"api error": {
+			interceptor: func(op *Operation, req *http.Request, next Invoker) (*http.Response, error) {
+				return newTestResponse(http.StatusNotFound, `{"status":404}`), nil
+			},
+			wantErr:    ErrNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		"error": {
+			interceptor: func(op *Operation, req *http.Request, next Invoker) (*http.Response, error) {
+				return nil, context.Canceled
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewWithOptions("test", WithHTTPClient(client), WithInterceptors(tt.interceptor))
+			if err != nil {
+				t.Fatalf("NewWithOptions() = %v", err)
+			}
+
+			// A previous call must not leave its response behind.
+			md := ResponseMetadata{StatusCode: http.StatusServiceUnavailable, Attempts: 3}
+			ctx := WithRequestOptions(context.Background(), WithResponseMetadata(&md))
+
+			customer, err := c.GetCustomer(ctx, "cus")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCustomer() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && customer.ExternalID != "cached" {
+				t.Errorf("ExternalID = %q, want cached", customer.ExternalID)
+			}
+			if md.StatusCode != tt.wantStatus || md.Attempts != 0 || md.Latency <= 0 {
+				t.Errorf("metadata = %+v", md)
+			}
+		})
+	}
+}
+
+func TestInterceptors_Resend(t *testing.T) {
+	var bodies []string
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			return newTestResponse(http.StatusConflict, `{"status":409}`), nil
+		}
+		return newTestResponse(http.StatusOK, `{"event":{"transaction_id":"tx_1"}}`), nil
+	})
+
+	retryConflicts := func(op *Operation, req *http.Request, next Invoker) (*http.Response, error) {
+		res, err := next(req)
+		if err != nil || res.StatusCode != http.StatusConflict {
+			return res, err
+		}
+		res.Body.Close()
+		return next(req)
+	}
+
+	c, err := NewWithOptions("test", WithHTTPClient(client), WithInterceptors(retryConflicts))
+	if err != nil {
+		t.Fatalf("NewWithOptions() = %v", err)
+	}
+
+	if _, err := c.CreateEvent(context.Background(), &EventInput{TransactionID: "tx_1"}); err != nil {
+		t.Fatalf("CreateEvent() = %v", err)
+	}
+
+	if len(bodies) != 2 || bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("bodies = %q, want the same body twice", bodies)
+	}
+}
@@ -0,0 +1,72 @@
+// Package redact replaces sensitive values in JSON documents and queries, for
+// the debug logs of the client and the cassettes of lagotest.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"slices"
+)
+
+// JSON replaces the values of the fields, at any depth of the JSON body, and
+// re-encodes it with sorted keys. Numbers are kept as they are written, so
+// large IDs and amounts are not rounded. It returns false when the body is
+// not a single JSON value.
+func JSON(body []byte, fields map[string]bool, replacement string) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, false
+	}
+
+	b, err := json.Marshal(Value(v, fields, replacement))
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// Value replaces the values of the fields, at any depth of the decoded JSON
+// value, in place.
+func Value(v any, fields map[string]bool, replacement string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if fields[k] {
+				v[k] = replacement
+				continue
+			}
+			v[k] = Value(val, fields, replacement)
+		}
+		return v
+	case []any:
+		for i, val := range v {
+			v[i] = Value(val, fields, replacement)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Query replaces the values of the fields in a copy of the query.
+func Query(q url.Values, fields map[string]bool, replacement string) url.Values {
+	redacted := make(url.Values, len(q))
+	for k, values := range q {
+		values = slices.Clone(values)
+		if fields[k] {
+			for i := range values {
+				values[i] = replacement
+			}
+		}
+		redacted[k] = values
+	}
+	return redacted
+}
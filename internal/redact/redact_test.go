@@ -0,0 +1,54 @@
+package redact
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	fields := map[string]bool{"email": true}
+
+	tests := map[string]struct {
+		body   string
+		want   string
+		wantOK bool
+	}{
+		"nested": {
+			body:   `{"customers":[{"email":"jane@example.com","name":"Jane"}]}`,
+			want:   `{"customers":[{"email":"x","name":"Jane"}]}`,
+			wantOK: true,
+		},
+		"large number": {
+			body:   `{"lago_id":9007199254740993,"amount":1.50}`,
+			want:   `{"amount":1.50,"lago_id":9007199254740993}`,
+			wantOK: true,
+		},
+		"not json": {
+			body: `<html>Bad Gateway</html>`,
+		},
+		"trailing data": {
+			body: `{"email":"jane@example.com"} {}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := JSON([]byte(tt.body), fields, "x")
+			if ok != tt.wantOK || string(got) != tt.want {
+				t.Errorf("JSON() = %s, %v, want %s, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	q := url.Values{"email": {"jane@example.com"}, "page": {"2"}}
+
+	got := Query(q, map[string]bool{"email": true}, "x")
+	if want := "email=x&page=2"; got.Encode() != want {
+		t.Errorf("Query() = %s, want %s", got.Encode(), want)
+	}
+	if q.Get("email") != "jane@example.com" {
+		t.Errorf("Query() changed the query to %s", q.Encode())
+	}
+}
@@ -95,6 +95,7 @@ type InvoiceOneOffInput struct {
 	ExternalCustomerId string              `json:"external_customer_id,omitempty"`
 	Currency           string              `json:"currency,omitempty"`
 	Fees               []*InvoiceFeesInput `json:"fees,omitempty"`
+	BillingEntityCode  string              `json:"billing_entity_code,omitempty"`
 }
 
 type InvoiceListInput struct {
@@ -108,6 +109,7 @@ type InvoiceListInput struct {
 	Status             InvoiceStatus        `json:"status,omitempty"`
 	PaymentStatus      InvoicePaymentStatus `json:"payment_status,omitempty"`
 	PaymentOverdue     bool                 `json:"payment_overdue,omitempty"`
+	BillingEntityCodes []string             `json:"billing_entity_codes,omitempty"`
 }
 
 func (i *InvoiceListInput) query() url.Values {
@@ -145,6 +147,10 @@ func (i *InvoiceListInput) query() url.Values {
 		q.Add("payment_overdue", strconv.FormatBool(i.PaymentOverdue))
 	}
 
+	for _, code := range i.BillingEntityCodes {
+		q.Add("billing_entity_codes[]", code)
+	}
+
 	return q
 }
 
@@ -222,8 +228,9 @@ type Invoice struct {
 	Metadata      []*InvoiceMetadataResponse `json:"metadata,omitempty"`
 	VersionNumber int                        `json:"version_number,omitempty"`
 
-	Customer      *Customer       `json:"customer,omitempty"`
-	Subscriptions []*Subscription `json:"subscriptions,omitempty"`
+	BillingEntityCode string          `json:"billing_entity_code,omitempty"`
+	Customer          *Customer       `json:"customer,omitempty"`
+	Subscriptions     []*Subscription `json:"subscriptions,omitempty"`
 
 	Fees                  []*Fee                   `json:"fees,omitempty"`
 	Credits               []*InvoiceCredit         `json:"credits,omitempty"`
@@ -237,7 +244,7 @@ type InvoicePaymentURL struct {
 }
 
 func (c *Client) GetInvoice(ctx context.Context, invoiceID string) (*Invoice, error) {
-	u := c.url("invoices/"+invoiceID, nil)
+	u := c.url("GetInvoice", "invoices/{lago_id}", nil, invoiceID)
 	result, err := get[invoiceResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -247,12 +254,12 @@ func (c *Client) GetInvoice(ctx context.Context, invoiceID string) (*Invoice, er
 }
 
 func (c *Client) ListInvoice(ctx context.Context, invoiceListInput *InvoiceListInput) (*InvoiceList, error) {
-	u := c.url("invoices", invoiceListInput.query())
+	u := c.url("ListInvoice", "invoices", invoiceListInput.query())
 	return get[InvoiceList](ctx, c, u)
 }
 
 func (c *Client) CreateInvoice(ctx context.Context, oneOffInput *InvoiceOneOffInput) (*Invoice, error) {
-	u := c.url("invoices", nil)
+	u := c.url("CreateInvoice", "invoices", nil)
 	result, err := post[invoiceOneOffParams, invoiceResult](
 		ctx,
 		c,
@@ -267,7 +274,7 @@ func (c *Client) CreateInvoice(ctx context.Context, oneOffInput *InvoiceOneOffIn
 }
 
 func (c *Client) UpdateInvoice(ctx context.Context, invoiceInput *InvoiceInput) (*Invoice, error) {
-	u := c.url("invoices/"+invoiceInput.LagoID.String(), nil)
+	u := c.url("UpdateInvoice", "invoices/{lago_id}", nil, invoiceInput.LagoID.String())
 	result, err := put[invoiceParams, invoiceResult](
 		ctx,
 		c,
@@ -282,7 +289,7 @@ func (c *Client) UpdateInvoice(ctx context.Context, invoiceInput *InvoiceInput)
 }
 
 func (c *Client) DownloadInvoice(ctx context.Context, invoiceID string) (*Invoice, error) {
-	u := c.url("invoices/"+invoiceID+"/download", nil)
+	u := c.url("DownloadInvoice", "invoices/{lago_id}/download", nil, invoiceID)
 	result, err := postWithoutBody[invoiceResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -292,7 +299,7 @@ func (c *Client) DownloadInvoice(ctx context.Context, invoiceID string) (*Invoic
 }
 
 func (c *Client) RefreshInvoice(ctx context.Context, invoiceID string) (*Invoice, error) {
-	u := c.url("invoices/"+invoiceID+"/refresh", nil)
+	u := c.url("RefreshInvoice", "invoices/{lago_id}/refresh", nil, invoiceID)
 	result, err := putWithoutBody[invoiceResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -302,7 +309,7 @@ func (c *Client) RefreshInvoice(ctx context.Context, invoiceID string) (*Invoice
 }
 
 func (c *Client) RetryInvoice(ctx context.Context, invoiceID string) (*Invoice, error) {
-	u := c.url("invoices/"+invoiceID+"/retry", nil)
+	u := c.url("RetryInvoice", "invoices/{lago_id}/retry", nil, invoiceID)
 	result, err := postWithoutBody[invoiceResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -312,7 +319,7 @@ func (c *Client) RetryInvoice(ctx context.Context, invoiceID string) (*Invoice,
 }
 
 func (c *Client) FinalizeInvoice(ctx context.Context, invoiceID string) (*Invoice, error) {
-	u := c.url("invoices/"+invoiceID+"/finalize", nil)
+	u := c.url("FinalizeInvoice", "invoices/{lago_id}/finalize", nil, invoiceID)
 	result, err := putWithoutBody[invoiceResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -322,7 +329,7 @@ func (c *Client) FinalizeInvoice(ctx context.Context, invoiceID string) (*Invoic
 }
 
 func (c *Client) LoseInvoiceDispute(ctx context.Context, invoiceID string) (*Invoice, error) {
-	u := c.url("invoices/"+invoiceID+"/lose_dispute", nil)
+	u := c.url("LoseInvoiceDispute", "invoices/{lago_id}/lose_dispute", nil, invoiceID)
 	result, err := putWithoutBody[invoiceResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -332,7 +339,7 @@ func (c *Client) LoseInvoiceDispute(ctx context.Context, invoiceID string) (*Inv
 }
 
 func (c *Client) RetryInvoicePayment(ctx context.Context, invoiceID string) (*Invoice, error) {
-	u := c.url("invoices/"+invoiceID+"/retry_payment", nil)
+	u := c.url("RetryInvoicePayment", "invoices/{lago_id}/retry_payment", nil, invoiceID)
 	result, err := postWithoutBody[invoiceResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -342,7 +349,7 @@ func (c *Client) RetryInvoicePayment(ctx context.Context, invoiceID string) (*In
 }
 
 func (c *Client) GetInvoicePaymentURL(ctx context.Context, invoiceID string) (*InvoicePaymentURL, error) {
-	u := c.url("invoices/"+invoiceID+"/payment_url", nil)
+	u := c.url("GetInvoicePaymentURL", "invoices/{lago_id}/payment_url", nil, invoiceID)
 	result, err := postWithoutBody[InvoicePaymentURLResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -40,6 +40,6 @@ type InvoiceCollection struct {
 }
 
 func (c *Client) ListInvoiceCollections(ctx context.Context, invoiceCollectionListInput *InvoiceCollectionListInput) (*InvoiceCollectionList, error) {
-	u := c.url("analytics/invoice_collection", invoiceCollectionListInput.query())
+	u := c.url("ListInvoiceCollections", "analytics/invoice_collection", invoiceCollectionListInput.query())
 	return get[InvoiceCollectionList](ctx, c, u)
 }
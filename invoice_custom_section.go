@@ -0,0 +1,121 @@
+package lago
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type invoiceCustomSectionParams struct {
+	InvoiceCustomSection *InvoiceCustomSectionInput `json:"invoice_custom_section"`
+}
+
+type InvoiceCustomSectionInput struct {
+	Code        string `json:"code,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Details     string `json:"details,omitempty"`
+
+	// AppliedToOrganization shows the section on the invoices of every
+	// customer that does not select its own sections.
+	AppliedToOrganization bool `json:"applied_to_organization,omitempty"`
+}
+
+type InvoiceCustomSectionListInput struct {
+	PerPage int `json:"per_page,omitempty,string"`
+	Page    int `json:"page,omitempty,string"`
+}
+
+func (i *InvoiceCustomSectionListInput) query() url.Values {
+	q := make(url.Values)
+	if i.PerPage > 0 {
+		q.Add("per_page", strconv.Itoa(i.PerPage))
+	}
+	if i.Page > 0 {
+		q.Add("page", strconv.Itoa(i.Page))
+	}
+	return q
+}
+
+type InvoiceCustomSectionList struct {
+	InvoiceCustomSections []*InvoiceCustomSection `json:"invoice_custom_sections,omitempty"`
+	Meta                  Metadata                `json:"meta,omitempty"`
+}
+
+type invoiceCustomSectionResult struct {
+	InvoiceCustomSection *InvoiceCustomSection `json:"invoice_custom_section,omitempty"`
+}
+
+// InvoiceCustomSection is a section of free text, such as bank details,
+// added to invoices.
+type InvoiceCustomSection struct {
+	LagoID      uuid.UUID `json:"lago_id,omitempty"`
+	Code        string    `json:"code,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Description string    `json:"description,omitempty"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Details     string    `json:"details,omitempty"`
+
+	AppliedToOrganization bool `json:"applied_to_organization,omitempty"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+func (c *Client) GetInvoiceCustomSection(ctx context.Context, invoiceCustomSectionCode string) (*InvoiceCustomSection, error) {
+	u := c.url("GetInvoiceCustomSection", "invoice_custom_sections/{code}", nil, invoiceCustomSectionCode)
+	result, err := get[invoiceCustomSectionResult](ctx, c, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.InvoiceCustomSection, nil
+}
+
+func (c *Client) ListInvoiceCustomSections(ctx context.Context, invoiceCustomSectionListInput *InvoiceCustomSectionListInput) (*InvoiceCustomSectionList, error) {
+	u := c.url("ListInvoiceCustomSections", "invoice_custom_sections", invoiceCustomSectionListInput.query())
+	return get[InvoiceCustomSectionList](ctx, c, u)
+}
+
+func (c *Client) CreateInvoiceCustomSection(ctx context.Context, invoiceCustomSectionInput *InvoiceCustomSectionInput) (*InvoiceCustomSection, error) {
+	u := c.url("CreateInvoiceCustomSection", "invoice_custom_sections", nil)
+	result, err := post[invoiceCustomSectionParams, invoiceCustomSectionResult](
+		ctx,
+		c,
+		u,
+		&invoiceCustomSectionParams{InvoiceCustomSection: invoiceCustomSectionInput},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.InvoiceCustomSection, nil
+}
+
+func (c *Client) UpdateInvoiceCustomSection(ctx context.Context, invoiceCustomSectionInput *InvoiceCustomSectionInput) (*InvoiceCustomSection, error) {
+	u := c.url("UpdateInvoiceCustomSection", "invoice_custom_sections/{code}", nil, invoiceCustomSectionInput.Code)
+	result, err := put[invoiceCustomSectionParams, invoiceCustomSectionResult](
+		ctx,
+		c,
+		u,
+		&invoiceCustomSectionParams{InvoiceCustomSection: invoiceCustomSectionInput},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.InvoiceCustomSection, nil
+}
+
+func (c *Client) DeleteInvoiceCustomSection(ctx context.Context, invoiceCustomSectionCode string) (*InvoiceCustomSection, error) {
+	u := c.url("DeleteInvoiceCustomSection", "invoice_custom_sections/{code}", nil, invoiceCustomSectionCode)
+	result, err := delete[invoiceCustomSectionResult](ctx, c, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.InvoiceCustomSection, nil
+}
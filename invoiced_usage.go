@@ -37,6 +37,6 @@ type InvoicedUsage struct {
 }
 
 func (c *Client) ListInvoiceUsages(ctx context.Context, invoicedUsageListInput *InvoicedUsageListInput) (*InvoicedUsageList, error) {
-	u := c.url("analytics/invoiced_usage", invoicedUsageListInput.query())
+	u := c.url("ListInvoiceUsages", "analytics/invoiced_usage", invoicedUsageListInput.query())
 	return get[InvoicedUsageList](ctx, c, u)
 }
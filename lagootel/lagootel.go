@@ -0,0 +1,239 @@
+// Package lagootel instruments the calls of a lago.Client with OpenTelemetry
+// traces and metrics.
+//
+// It is a module of its own, so the core client does not depend on
+// OpenTelemetry. It is an interceptor, so it works with any lago.HTTPClient,
+// and costs nothing when it is not installed:
+//
+//	client, err := lago.NewWithOptions(apiKey,
+//		lago.WithInterceptors(lagootel.NewInterceptor()),
+//	)
+//
+// It requires a published version of the client. To work on both at once,
+// use a workspace, which is not committed:
+//
+//	go work init . ./lagootel
+//
+// Every operation is traced by a client span named after it, such as
+// "lago.CreateEvent", whose context is propagated to the API in the request
+// headers. Retries and rate limiting happen within the span.
+package lagootel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	lago "github.com/nikola-jokic/lago-go"
+)
+
+// ScopeName is the instrumentation scope of the tracer and the meter.
+const ScopeName = "github.com/nikola-jokic/lago-go/lagootel"
+
+// Attributes recorded on the spans and the metrics.
+const (
+	// OperationKey is the name of the operation, such as "CreateEvent".
+	OperationKey = attribute.Key("lago.operation")
+	// ResourceKey is the top-level resource of the operation.
+	ResourceKey = attribute.Key("lago.resource")
+	// OutcomeKey is the outcome of the operation, such as OutcomeSuccess.
+	OutcomeKey = attribute.Key("lago.outcome")
+	// RequestIDKey is the request ID returned by the API.
+	RequestIDKey = attribute.Key("lago.request_id")
+	// ErrorCodeKey is the error code returned by the API, such as
+	// "customer_not_found".
+	ErrorCodeKey = attribute.Key("lago.error_code")
+)
+
+// The path parameters of the operation, such as external_customer_id, are
+// recorded on the spans with this prefix, as "lago.external_customer_id".
+const pathParamPrefix = "lago."
+
+// Outcomes of an operation.
+const (
+	// OutcomeSuccess is a successful response.
+	OutcomeSuccess = "success"
+	// OutcomeAPIError is an error response of the API.
+	OutcomeAPIError = "api_error"
+	// OutcomeError is a failure to get a response.
+	OutcomeError = "error"
+)
+
+// maxErrorBodySize limits how much of an error response is read to find its
+// error code.
+const maxErrorBodySize = 64 << 10
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// Option configures the interceptor returned by NewInterceptor.
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider. Defaults to the global one.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider. Defaults to the global one.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators sets the propagator injecting the trace context into the
+// request headers. Defaults to the global one.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = p
+	}
+}
+
+type instrumentation struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	operations metric.Int64Counter
+	duration   metric.Float64Histogram
+}
+
+// NewInterceptor returns an interceptor tracing the operations and recording
+// the lago.client.operations counter and the lago.client.operation.duration
+// histogram, per operation and outcome.
+func NewInterceptor(opts ...Option) lago.Interceptor {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+	operations, err := meter.Int64Counter("lago.client.operations",
+		metric.WithDescription("Number of Lago API operations."),
+		metric.WithUnit("{operation}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	duration, err := meter.Float64Histogram("lago.client.operation.duration",
+		metric.WithDescription("Duration of Lago API operations, including retries."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	in := &instrumentation{
+		tracer:     cfg.tracerProvider.Tracer(ScopeName),
+		propagator: cfg.propagator,
+		operations: operations,
+		duration:   duration,
+	}
+	return in.intercept
+}
+
+func (in *instrumentation) intercept(op *lago.Operation, req *http.Request, next lago.Invoker) (*http.Response, error) {
+	attrs := []attribute.KeyValue{
+		OperationKey.String(op.Name),
+		ResourceKey.String(op.Resource),
+		semconv.HTTPRequestMethodKey.String(op.Method),
+	}
+
+	spanAttrs := slices.Concat(attrs, []attribute.KeyValue{
+		semconv.URLTemplate(lago.ApiV1Path + op.PathTemplate),
+		semconv.ServerAddress(req.URL.Hostname()),
+	})
+	for k, v := range op.PathParams {
+		spanAttrs = append(spanAttrs, attribute.String(pathParamPrefix+k, v))
+	}
+
+	ctx, span := in.tracer.Start(req.Context(), "lago."+op.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(spanAttrs...),
+	)
+	defer span.End()
+
+	req = req.WithContext(ctx)
+	in.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	res, err := next(req)
+	elapsed := time.Since(start).Seconds()
+
+	outcome := OutcomeSuccess
+	switch {
+	case err != nil:
+		outcome = OutcomeError
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+	case res.StatusCode >= http.StatusBadRequest:
+		outcome = OutcomeAPIError
+		status := strconv.Itoa(res.StatusCode)
+		span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+		span.SetAttributes(semconv.ErrorTypeKey.String(status))
+		if code := errorCode(res); code != "" {
+			span.SetAttributes(ErrorCodeKey.String(code))
+		}
+	}
+
+	if res != nil {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(res.StatusCode))
+		span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+		if id := res.Header.Get(lago.RequestIDHeader); id != "" {
+			span.SetAttributes(RequestIDKey.String(id))
+		}
+	}
+	attrs = append(attrs, OutcomeKey.String(outcome))
+
+	set := metric.WithAttributeSet(attribute.NewSet(attrs...))
+	if in.operations != nil {
+		in.operations.Add(ctx, 1, set)
+	}
+	if in.duration != nil {
+		in.duration.Record(ctx, elapsed, set)
+	}
+
+	return res, err
+}
+
+// errorCode returns the error code of an error response. The body is read
+// and replaced, so it can still be read by the client.
+func errorCode(res *http.Response) string {
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+	if err != nil {
+		return ""
+	}
+
+	var e struct {
+		Code string `json:"code"`
+	}
+	if json.Unmarshal(body, &e) != nil {
+		return ""
+	}
+	return e.Code
+}
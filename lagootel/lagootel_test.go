@@ -0,0 +1,179 @@
+package lagootel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	lago "github.com/nikola-jokic/lago-go"
+)
+
+type httpClientFunc func(req *http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewInterceptor(t *testing.T) {
+	tests := map[string]struct {
+		status      int
+		body        string
+		sendErr     error
+		wantErr     error
+		wantOutcome string
+		wantStatus  codes.Code
+		wantAttrs   map[attribute.Key]string
+	}{
+		"success": {
+			status:      http.StatusOK,
+			body:        `{"customer":{"external_id":"cus_1"}}`,
+			wantOutcome: OutcomeSuccess,
+			wantStatus:  codes.Unset,
+			wantAttrs: map[attribute.Key]string{
+				RequestIDKey:                "req-1",
+				"http.response.status_code": "200",
+			},
+		},
+		"api error": {
+			status:      http.StatusNotFound,
+			body:        `{"status":404,"error":"Not Found","code":"customer_not_found"}`,
+			wantErr:     lago.ErrNotFound,
+			wantOutcome: OutcomeAPIError,
+			wantStatus:  codes.Error,
+			wantAttrs: map[attribute.Key]string{
+				ErrorCodeKey:                "customer_not_found",
+				"error.type":                "404",
+				"http.response.status_code": "404",
+			},
+		},
+		"error": {
+			sendErr:     errors.New("connection refused"),
+			wantOutcome: OutcomeError,
+			wantStatus:  codes.Error,
+			wantAttrs: map[attribute.Key]string{
+				"error.type": "*errors.errorString",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sent *http.Request
+			client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				sent = req
+				if tt.sendErr != nil {
+					return nil, tt.sendErr
+				}
+				res := &http.Response{
+					StatusCode: tt.status,
+					Header:     make(http.Header),
+					Body:       io.NopCloser(strings.NewReader(tt.body)),
+					Request:    req,
+				}
+				res.Header.Set(lago.RequestIDHeader, "req-1")
+				return res, nil
+			})
+
+			spans := tracetest.NewSpanRecorder()
+			reader := sdkmetric.NewManualReader()
+			interceptor := NewInterceptor(
+				WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+				WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+				WithPropagators(propagation.TraceContext{}),
+			)
+
+			c, err := lago.NewWithOptions("test",
+				lago.WithHTTPClient(client),
+				lago.WithInterceptors(interceptor),
+			)
+			if err != nil {
+				t.Fatalf("NewWithOptions() = %v", err)
+			}
+
+			_, err = c.GetCustomer(context.Background(), "cus_1")
+			if tt.sendErr != nil {
+				if !errors.Is(err, tt.sendErr) {
+					t.Fatalf("GetCustomer() = %v, want %v", err, tt.sendErr)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCustomer() = %v, want %v", err, tt.wantErr)
+			}
+
+			ended := spans.Ended()
+			if len(ended) != 1 {
+				t.Fatalf("got %d spans, want 1", len(ended))
+			}
+			span := ended[0]
+			if span.Name() != "lago.GetCustomer" {
+				t.Errorf("span name = %q, want lago.GetCustomer", span.Name())
+			}
+			if span.Status().Code != tt.wantStatus {
+				t.Errorf("span status = %v, want %v", span.Status().Code, tt.wantStatus)
+			}
+
+			want := map[attribute.Key]string{
+				OperationKey:                "GetCustomer",
+				ResourceKey:                 "customers",
+				"lago.external_customer_id": "cus_1",
+				"http.request.method":       http.MethodGet,
+				"url.template":              "/api/v1/customers/{external_customer_id}",
+				"server.address":            "api.getlago.com",
+			}
+			for k, v := range tt.wantAttrs {
+				want[k] = v
+			}
+			got := make(map[attribute.Key]string)
+			for _, kv := range span.Attributes() {
+				got[kv.Key] = kv.Value.Emit()
+			}
+			for k, v := range want {
+				if got[k] != v {
+					t.Errorf("span attribute %s = %q, want %q", k, got[k], v)
+				}
+			}
+
+			traceparent := sent.Header.Get("Traceparent")
+			if !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+				t.Errorf("traceparent = %q, want trace %s", traceparent, span.SpanContext().TraceID())
+			}
+
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &rm); err != nil {
+				t.Fatalf("Collect() = %v", err)
+			}
+			metrics := make(map[string]metricdata.Metrics)
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					metrics[m.Name] = m
+				}
+			}
+
+			count, ok := metrics["lago.client.operations"].Data.(metricdata.Sum[int64])
+			if !ok || len(count.DataPoints) != 1 || count.DataPoints[0].Value != 1 {
+				t.Fatalf("lago.client.operations = %+v", metrics["lago.client.operations"].Data)
+			}
+			if outcome, _ := count.DataPoints[0].Attributes.Value(OutcomeKey); outcome.AsString() != tt.wantOutcome {
+				t.Errorf("outcome = %q, want %q", outcome.AsString(), tt.wantOutcome)
+			}
+			if _, ok := count.DataPoints[0].Attributes.Value("lago.external_customer_id"); ok {
+				t.Error("metric has a resource ID attribute")
+			}
+
+			duration, ok := metrics["lago.client.operation.duration"].Data.(metricdata.Histogram[float64])
+			if !ok || len(duration.DataPoints) != 1 || duration.DataPoints[0].Count != 1 {
+				t.Errorf("lago.client.operation.duration = %+v", metrics["lago.client.operation.duration"].Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,1196 @@
+package lagotest
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	lago "github.com/nikola-jokic/lago-go"
+)
+
+func (s *Server) routes(mux *http.ServeMux) {
+	const p = lago.ApiV1Path
+
+	mux.HandleFunc("POST "+p+"customers", s.createCustomer)
+	mux.HandleFunc("GET "+p+"customers", s.listCustomers)
+	mux.HandleFunc("GET "+p+"customers/{external_id}", s.getCustomer)
+	mux.HandleFunc("DELETE "+p+"customers/{external_id}", s.deleteCustomer)
+	mux.HandleFunc("DELETE "+p+"customers/{external_id}/applied_coupons/{id}", s.deleteAppliedCoupon)
+
+	mux.HandleFunc("POST "+p+"plans", s.createPlan)
+	mux.HandleFunc("GET "+p+"plans", s.listPlans)
+	mux.HandleFunc("GET "+p+"plans/{code}", s.getPlan)
+	mux.HandleFunc("PUT "+p+"plans/{code}", s.updatePlan)
+	mux.HandleFunc("DELETE "+p+"plans/{code}", s.deletePlan)
+
+	mux.HandleFunc("POST "+p+"billable_metrics", s.createBillableMetric)
+	mux.HandleFunc("GET "+p+"billable_metrics", s.listBillableMetrics)
+	mux.HandleFunc("GET "+p+"billable_metrics/{code}", s.getBillableMetric)
+	mux.HandleFunc("PUT "+p+"billable_metrics/{code}", s.updateBillableMetric)
+	mux.HandleFunc("DELETE "+p+"billable_metrics/{code}", s.deleteBillableMetric)
+
+	mux.HandleFunc("POST "+p+"subscriptions", s.createSubscription)
+	mux.HandleFunc("GET "+p+"subscriptions", s.listSubscriptions)
+	mux.HandleFunc("GET "+p+"subscriptions/{external_id}", s.getSubscription)
+	mux.HandleFunc("PUT "+p+"subscriptions/{external_id}", s.updateSubscription)
+	mux.HandleFunc("DELETE "+p+"subscriptions/{external_id}", s.terminateSubscription)
+
+	mux.HandleFunc("POST "+p+"events", s.createEvent)
+	mux.HandleFunc("POST "+p+"events/batch", s.batchEvents)
+	mux.HandleFunc("GET "+p+"events/{transaction_id}", s.getEvent)
+
+	mux.HandleFunc("POST "+p+"wallets", s.createWallet)
+	mux.HandleFunc("GET "+p+"wallets", s.listWallets)
+	mux.HandleFunc("GET "+p+"wallets/{id}", s.getWallet)
+	mux.HandleFunc("PUT "+p+"wallets/{id}", s.updateWallet)
+	mux.HandleFunc("DELETE "+p+"wallets/{id}", s.terminateWallet)
+
+	mux.HandleFunc("POST "+p+"coupons", s.createCoupon)
+	mux.HandleFunc("GET "+p+"coupons", s.listCoupons)
+	mux.HandleFunc("GET "+p+"coupons/{code}", s.getCoupon)
+	mux.HandleFunc("PUT "+p+"coupons/{code}", s.updateCoupon)
+	mux.HandleFunc("DELETE "+p+"coupons/{code}", s.deleteCoupon)
+
+	mux.HandleFunc("POST "+p+"applied_coupons", s.applyCoupon)
+	mux.HandleFunc("GET "+p+"applied_coupons", s.listAppliedCoupons)
+
+	mux.HandleFunc("POST "+p+"invoices", s.createInvoice)
+	mux.HandleFunc("GET "+p+"invoices", s.listInvoices)
+	mux.HandleFunc("GET "+p+"invoices/{id}", s.getInvoice)
+	mux.HandleFunc("PUT "+p+"invoices/{id}", s.updateInvoice)
+	mux.HandleFunc("PUT "+p+"invoices/{id}/finalize", s.finalizeInvoice)
+
+	mux.HandleFunc("POST "+p+"webhook_endpoints", s.createWebhookEndpoint)
+	mux.HandleFunc("GET "+p+"webhook_endpoints", s.listWebhookEndpoints)
+	mux.HandleFunc("GET "+p+"webhook_endpoints/{id}", s.getWebhookEndpoint)
+	mux.HandleFunc("PUT "+p+"webhook_endpoints/{id}", s.updateWebhookEndpoint)
+	mux.HandleFunc("DELETE "+p+"webhook_endpoints/{id}", s.deleteWebhookEndpoint)
+}
+
+// Customers
+
+func (s *Server) createCustomer(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Customer *lago.CustomerInput `json:"customer"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	in := params.Customer
+	if in == nil || in.ExternalID == "" {
+		writeValidationError(w, "external_id", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	customer, ok := s.customers.get(in.ExternalID)
+	if !ok {
+		customer = &lago.Customer{
+			LagoID:       uuid.New(),
+			SequentialID: len(s.customers.keys) + 1,
+			ExternalID:   in.ExternalID,
+			CreatedAt:    now,
+		}
+	}
+
+	customer.Name = in.Name
+	customer.Firstname = in.Firstname
+	customer.Lastname = in.Lastname
+	customer.CustomerType = string(in.CustomerType)
+	customer.Email = in.Email
+	customer.AddressLine1 = in.AddressLine1
+	customer.AddressLine2 = in.AddressLine2
+	customer.City = in.City
+	customer.Zipcode = in.Zipcode
+	customer.State = in.State
+	customer.Country = in.Country
+	customer.LegalName = in.LegalName
+	customer.LegalNumber = in.LegalNumber
+	customer.NetPaymentTerm = in.NetPaymentTerm
+	customer.TaxIdentificationNumber = in.TaxIdentificationNumber
+	customer.Phone = in.Phone
+	customer.URL = in.URL
+	customer.Currency = in.Currency
+	customer.Timezone = in.Timezone
+	customer.FinalizeZeroAmountInvoice = in.FinalizeZeroAmountInvoice
+	customer.BillingEntityCode = in.BillingEntityCode
+	customer.UpdatedAt = now
+
+	s.customers.put(customer.ExternalID, customer)
+	writeJSON(w, http.StatusOK, map[string]any{"customer": customer})
+}
+
+func (s *Server) listCustomers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customers, meta := paginate(r, s.customers.list(nil))
+	writeJSON(w, http.StatusOK, &lago.CustomerList{Customers: customers, Meta: meta})
+}
+
+func (s *Server) getCustomer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, ok := s.customers.get(r.PathValue("external_id"))
+	if !ok {
+		writeNotFound(w, "customer")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"customer": customer})
+}
+
+func (s *Server) deleteCustomer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, ok := s.customers.remove(r.PathValue("external_id"))
+	if !ok {
+		writeNotFound(w, "customer")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"customer": customer})
+}
+
+// Plans
+
+func (s *Server) createPlan(w http.ResponseWriter, r *http.Request) {
+	s.upsertPlan(w, r, "")
+}
+
+func (s *Server) updatePlan(w http.ResponseWriter, r *http.Request) {
+	s.upsertPlan(w, r, r.PathValue("code"))
+}
+
+func (s *Server) upsertPlan(w http.ResponseWriter, r *http.Request, code string) {
+	var params struct {
+		Plan *lago.PlanInput `json:"plan"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	in := params.Plan
+	if in == nil || in.Code == "" {
+		writeValidationError(w, "code", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan, exists := s.plans.get(in.Code)
+	switch {
+	case code == "" && exists:
+		writeValidationError(w, "code", "value_already_exist")
+		return
+	case code != "":
+		if plan, exists = s.plans.get(code); !exists {
+			writeNotFound(w, "plan")
+			return
+		}
+		s.plans.remove(code)
+	default:
+		plan = &lago.Plan{LagoID: uuid.New()}
+	}
+
+	plan.Name = in.Name
+	plan.InvoiceDisplayName = in.InvoiceDisplayName
+	plan.Code = in.Code
+	plan.Interval = in.Interval
+	plan.Description = in.Description
+	plan.AmountCents = in.AmountCents
+	plan.AmountCurrency = in.AmountCurrency
+	plan.PayInAdvance = in.PayInAdvance
+	plan.BillChargeMonthly = in.BillChargeMonthly
+
+	plan.Charges = nil
+	for _, ci := range in.Charges {
+		charge := &lago.Charge{
+			LagoID:               uuid.New(),
+			LagoBillableMetricID: ci.BillableMetricID,
+			ChargeModel:          ci.ChargeModel,
+			CreatedAt:            time.Now().UTC(),
+			PayInAdvance:         ci.PayInAdvance,
+			Invoiceable:          ci.Invoiceable,
+			RegroupPaidFees:      ci.RegroupPaidFees,
+			Prorated:             ci.Prorated,
+			MinAmountCents:       ci.MinAmountCents,
+			Properties:           ci.Properties,
+			Filters:              ci.Filters,
+		}
+		for _, bm := range s.billableMetrics.list(nil) {
+			if bm.LagoID == ci.BillableMetricID {
+				charge.BillableMetricCode = bm.Code
+			}
+		}
+		plan.Charges = append(plan.Charges, charge)
+	}
+
+	s.plans.put(plan.Code, plan)
+	writeJSON(w, http.StatusOK, map[string]any{"plan": plan})
+}
+
+func (s *Server) listPlans(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plans, meta := paginate(r, s.plans.list(nil))
+	writeJSON(w, http.StatusOK, &lago.PlanList{Plans: plans, Meta: meta})
+}
+
+func (s *Server) getPlan(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan, ok := s.plans.get(r.PathValue("code"))
+	if !ok {
+		writeNotFound(w, "plan")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"plan": plan})
+}
+
+func (s *Server) deletePlan(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan, ok := s.plans.remove(r.PathValue("code"))
+	if !ok {
+		writeNotFound(w, "plan")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"plan": plan})
+}
+
+// Billable metrics
+
+func (s *Server) createBillableMetric(w http.ResponseWriter, r *http.Request) {
+	s.upsertBillableMetric(w, r, "")
+}
+
+func (s *Server) updateBillableMetric(w http.ResponseWriter, r *http.Request) {
+	s.upsertBillableMetric(w, r, r.PathValue("code"))
+}
+
+func (s *Server) upsertBillableMetric(w http.ResponseWriter, r *http.Request, code string) {
+	var params struct {
+		BillableMetric *lago.BillableMetricInput `json:"billable_metric"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	in := params.BillableMetric
+	if in == nil || in.Code == "" {
+		writeValidationError(w, "code", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bm, exists := s.billableMetrics.get(in.Code)
+	switch {
+	case code == "" && exists:
+		writeValidationError(w, "code", "value_already_exist")
+		return
+	case code != "":
+		if bm, exists = s.billableMetrics.get(code); !exists {
+			writeNotFound(w, "billable_metric")
+			return
+		}
+		s.billableMetrics.remove(code)
+	default:
+		bm = &lago.BillableMetric{LagoID: uuid.New(), CreatedAt: time.Now().UTC()}
+	}
+
+	bm.Name = in.Name
+	bm.Code = in.Code
+	bm.Description = in.Description
+	bm.Recurring = in.Recurring
+	bm.RoundingFunction = in.RoundingFunction
+	bm.RoundingPrecision = in.RoundingPrecision
+	bm.AggregationType = in.AggregationType
+	bm.Expression = in.Expression
+	bm.FieldName = in.FieldName
+	bm.Filters = in.Filters
+	bm.WeightedInterval = nil
+	if in.WeightedInterval != "" {
+		bm.WeightedInterval = &in.WeightedInterval
+	}
+
+	s.billableMetrics.put(bm.Code, bm)
+	writeJSON(w, http.StatusOK, map[string]any{"billable_metric": bm})
+}
+
+func (s *Server) listBillableMetrics(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bms, meta := paginate(r, s.billableMetrics.list(nil))
+	writeJSON(w, http.StatusOK, &lago.BillableMetricList{BillableMetrics: bms, Meta: meta})
+}
+
+func (s *Server) getBillableMetric(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bm, ok := s.billableMetrics.get(r.PathValue("code"))
+	if !ok {
+		writeNotFound(w, "billable_metric")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"billable_metric": bm})
+}
+
+func (s *Server) deleteBillableMetric(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bm, ok := s.billableMetrics.remove(r.PathValue("code"))
+	if !ok {
+		writeNotFound(w, "billable_metric")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"billable_metric": bm})
+}
+
+// Subscriptions
+
+func (s *Server) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Subscription *lago.SubscriptionInput `json:"subscription"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	in := params.Subscription
+	if in == nil || in.ExternalID == "" {
+		writeValidationError(w, "external_id", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, ok := s.customers.get(in.ExternalCustomerID)
+	if !ok {
+		writeNotFound(w, "customer")
+		return
+	}
+	plan, ok := s.plans.get(in.PlanCode)
+	if !ok {
+		writeNotFound(w, "plan")
+		return
+	}
+	if _, ok := s.subscriptions.get(in.ExternalID); ok {
+		writeValidationError(w, "external_id", "value_already_exist")
+		return
+	}
+
+	now := time.Now().UTC()
+	subscriptionAt := now
+	if in.SubscriptionAt != nil {
+		subscriptionAt = *in.SubscriptionAt
+	}
+
+	billingTime := in.BillingTime
+	if billingTime == "" {
+		billingTime = lago.Calendar
+	}
+
+	sub := &lago.Subscription{
+		LagoID:             uuid.New(),
+		LagoCustomerID:     customer.LagoID,
+		ExternalCustomerID: customer.ExternalID,
+		ExternalID:         in.ExternalID,
+		PlanCode:           plan.Code,
+		Name:               in.Name,
+		Status:             lago.SubscriptionStatusActive,
+		BillingTime:        billingTime,
+		SubscriptionAt:     &subscriptionAt,
+		EndingAt:           in.EndingAt,
+		Plan:               plan,
+		CreatedAt:          &now,
+		StartedAt:          &now,
+	}
+	if subscriptionAt.After(now) {
+		sub.Status = lago.SubscriptionStatusPending
+		sub.StartedAt = nil
+	}
+
+	s.subscriptions.put(sub.ExternalID, sub)
+	writeJSON(w, http.StatusOK, map[string]any{"subscription": sub})
+}
+
+func (s *Server) updateSubscription(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Subscription *lago.SubscriptionInput `json:"subscription"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions.get(r.PathValue("external_id"))
+	if !ok {
+		writeNotFound(w, "subscription")
+		return
+	}
+
+	if in := params.Subscription; in != nil {
+		if in.Name != "" {
+			sub.Name = in.Name
+		}
+		if in.EndingAt != nil {
+			sub.EndingAt = in.EndingAt
+		}
+		if in.SubscriptionAt != nil {
+			sub.SubscriptionAt = in.SubscriptionAt
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"subscription": sub})
+}
+
+func (s *Server) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	externalCustomerID := q.Get("external_customer_id")
+	planCode := q.Get("plan_code")
+	statuses := q["status[]"]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := s.subscriptions.list(func(sub *lago.Subscription) bool {
+		if externalCustomerID != "" && sub.ExternalCustomerID != externalCustomerID {
+			return false
+		}
+		if planCode != "" && sub.PlanCode != planCode {
+			return false
+		}
+		if len(statuses) > 0 {
+			return slices.Contains(statuses, string(sub.Status))
+		}
+		// Lago only lists active subscriptions by default.
+		return sub.Status == lago.SubscriptionStatusActive
+	})
+
+	subs, meta := paginate(r, subs)
+	writeJSON(w, http.StatusOK, &lago.SubscriptionList{Subscriptions: subs, Meta: meta})
+}
+
+func (s *Server) getSubscription(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions.get(r.PathValue("external_id"))
+	if !ok {
+		writeNotFound(w, "subscription")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"subscription": sub})
+}
+
+func (s *Server) terminateSubscription(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions.get(r.PathValue("external_id"))
+	if !ok {
+		writeNotFound(w, "subscription")
+		return
+	}
+
+	now := time.Now().UTC()
+	if sub.Status == lago.SubscriptionStatusPending {
+		sub.Status = lago.SubscriptionStatusCanceled
+		sub.CanceledAt = &now
+	} else {
+		sub.Status = lago.SubscriptionStatusTerminated
+		sub.TerminatedAt = &now
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"subscription": sub})
+}
+
+// Events
+
+// newEvent validates the input, returning the event to store or the
+// validation errors of the input.
+func (s *Server) newEvent(in *lago.EventInput, pending map[string]bool) (*lago.Event, map[string][]string) {
+	if in.TransactionID == "" {
+		return nil, map[string][]string{"transaction_id": {"value_is_mandatory"}}
+	}
+	if _, ok := s.events.get(in.TransactionID); ok || pending[in.TransactionID] {
+		return nil, map[string][]string{"transaction_id": {"value_already_exist"}}
+	}
+	if in.Code == "" {
+		return nil, map[string][]string{"code": {"value_is_mandatory"}}
+	}
+
+	now := time.Now().UTC()
+	timestamp := now
+	if in.Timestamp != "" {
+		if seconds, err := strconv.ParseFloat(in.Timestamp, 64); err == nil {
+			timestamp = time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+		} else if t, err := time.Parse(time.RFC3339, in.Timestamp); err == nil {
+			timestamp = t
+		}
+	}
+
+	event := &lago.Event{
+		LagoID:                  uuid.New(),
+		TransactionID:           in.TransactionID,
+		Code:                    in.Code,
+		Timestamp:               timestamp,
+		PreciseTotalAmountCents: in.PreciseTotalAmountCents,
+		Properties:              in.Properties,
+		ExternalSubscriptionID:  in.ExternalSubscriptionID,
+		CreatedAt:               now,
+	}
+	if sub, ok := s.subscriptions.get(in.ExternalSubscriptionID); ok {
+		event.LagoSubscriptionID = &sub.LagoID
+		event.LagoCustomerID = &sub.LagoCustomerID
+	}
+	return event, nil
+}
+
+func (s *Server) createEvent(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Event *lago.EventInput `json:"event"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	if params.Event == nil {
+		writeValidationError(w, "event", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event, details := s.newEvent(params.Event, nil)
+	if details != nil {
+		writeError(w, http.StatusUnprocessableEntity, "validation_errors", details)
+		return
+	}
+
+	s.events.put(event.TransactionID, event)
+	writeJSON(w, http.StatusOK, &lago.EventResult{Event: event})
+}
+
+// batchEvents stores the events only if all of them are valid, like Lago.
+func (s *Server) batchEvents(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Events []*lago.EventInput `json:"events"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	if len(params.Events) == 0 || len(params.Events) > lago.MaxBatchEvents {
+		writeValidationError(w, "events", "too_many_events")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*lago.Event
+	details := make(lago.ErrorDetail)
+	pending := make(map[string]bool)
+	for idx, in := range params.Events {
+		event, errs := s.newEvent(in, pending)
+		if errs != nil {
+			details[idx] = errs
+			continue
+		}
+		pending[event.TransactionID] = true
+		events = append(events, event)
+	}
+
+	if len(details) > 0 {
+		writeError(w, http.StatusUnprocessableEntity, "validation_errors", details)
+		return
+	}
+
+	for _, event := range events {
+		s.events.put(event.TransactionID, event)
+	}
+	writeJSON(w, http.StatusOK, &lago.BatchEventResult{Events: &events})
+}
+
+func (s *Server) getEvent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event, ok := s.events.get(r.PathValue("transaction_id"))
+	if !ok {
+		writeNotFound(w, "event")
+		return
+	}
+	writeJSON(w, http.StatusOK, &lago.EventResult{Event: event})
+}
+
+// Wallets
+
+func (s *Server) createWallet(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Wallet *lago.WalletInput `json:"wallet"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	in := params.Wallet
+	if in == nil {
+		writeValidationError(w, "wallet", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, ok := s.customers.get(in.ExternalCustomerID)
+	if !ok {
+		writeNotFound(w, "customer")
+		return
+	}
+
+	rate, err := strconv.ParseFloat(in.RateAmount, 64)
+	if err != nil || rate <= 0 {
+		writeValidationError(w, "rate_amount", "invalid_value")
+		return
+	}
+	paid, _ := strconv.ParseFloat(in.PaidCredits, 64)
+	granted, _ := strconv.ParseFloat(in.GrantedCredits, 64)
+	credits := paid + granted
+
+	wallet := &lago.Wallet{
+		LagoID:                           uuid.New(),
+		LagoCustomerID:                   customer.LagoID,
+		ExternalCustomerID:               customer.ExternalID,
+		Status:                           lago.Active,
+		Currency:                         in.Currency,
+		Name:                             in.Name,
+		RateAmount:                       in.RateAmount,
+		CreditsBalance:                   strconv.FormatFloat(credits, 'f', -1, 64),
+		BalanceCents:                     int(credits * rate * 100),
+		ConsumedCredits:                  "0",
+		InvoiceRequiresSuccessfulPayment: in.InvoiceRequiresSuccessfulPayment,
+		CreatedAt:                        time.Now().UTC(),
+	}
+	wallet.CreditsOngoingBalance = wallet.CreditsBalance
+	wallet.OngoingBalanceCents = wallet.BalanceCents
+	if in.ExpirationAt != nil {
+		wallet.ExpirationAt = *in.ExpirationAt
+	}
+
+	s.wallets.put(wallet.LagoID.String(), wallet)
+	writeJSON(w, http.StatusOK, map[string]any{"wallet": wallet})
+}
+
+func (s *Server) updateWallet(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Wallet *lago.WalletInput `json:"wallet"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallet, ok := s.wallets.get(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, "wallet")
+		return
+	}
+
+	if in := params.Wallet; in != nil {
+		if in.Name != "" {
+			wallet.Name = in.Name
+		}
+		if in.ExpirationAt != nil {
+			wallet.ExpirationAt = *in.ExpirationAt
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"wallet": wallet})
+}
+
+func (s *Server) listWallets(w http.ResponseWriter, r *http.Request) {
+	externalCustomerID := r.URL.Query().Get("external_customer_id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallets := s.wallets.list(func(wallet *lago.Wallet) bool {
+		return externalCustomerID == "" || wallet.ExternalCustomerID == externalCustomerID
+	})
+
+	wallets, meta := paginate(r, wallets)
+	writeJSON(w, http.StatusOK, &lago.WalletList{Wallets: wallets, Meta: meta})
+}
+
+func (s *Server) getWallet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallet, ok := s.wallets.get(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, "wallet")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"wallet": wallet})
+}
+
+func (s *Server) terminateWallet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallet, ok := s.wallets.get(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, "wallet")
+		return
+	}
+
+	wallet.Status = lago.Terminated
+	wallet.TerminatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, map[string]any{"wallet": wallet})
+}
+
+// Coupons
+
+func (s *Server) createCoupon(w http.ResponseWriter, r *http.Request) {
+	s.upsertCoupon(w, r, "")
+}
+
+func (s *Server) updateCoupon(w http.ResponseWriter, r *http.Request) {
+	s.upsertCoupon(w, r, r.PathValue("code"))
+}
+
+func (s *Server) upsertCoupon(w http.ResponseWriter, r *http.Request, code string) {
+	var params struct {
+		Coupon *lago.CouponInput `json:"coupon"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	in := params.Coupon
+	if in == nil || in.Code == "" {
+		writeValidationError(w, "code", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	coupon, exists := s.coupons.get(in.Code)
+	switch {
+	case code == "" && exists:
+		writeValidationError(w, "code", "value_already_exist")
+		return
+	case code != "":
+		if coupon, exists = s.coupons.get(code); !exists {
+			writeNotFound(w, "coupon")
+			return
+		}
+		s.coupons.remove(code)
+	default:
+		coupon = &lago.Coupon{LagoID: uuid.New(), CreatedAt: time.Now().UTC()}
+	}
+
+	coupon.Name = in.Name
+	coupon.Code = in.Code
+	coupon.Description = in.Description
+	coupon.AmountCents = in.AmountCents
+	coupon.AmountCurrency = in.AmountCurrency
+	coupon.Expiration = in.Expiration
+	coupon.ExpirationAt = in.ExpirationAt
+	coupon.PercentageRate = in.PercentageRate
+	coupon.CouponType = in.CouponType
+	coupon.Frequency = in.Frequency
+	coupon.Reusable = in.Reusable
+	coupon.FrequencyDuration = in.FrequencyDuration
+	coupon.PlanCodes = in.AppliesTo.PlanCodes
+	coupon.LimitedPlans = len(in.AppliesTo.PlanCodes) > 0
+	coupon.BillableMetricCodes = in.AppliesTo.BillableMetricCodes
+	coupon.LimitedBillableMetrics = len(in.AppliesTo.BillableMetricCodes) > 0
+
+	s.coupons.put(coupon.Code, coupon)
+	writeJSON(w, http.StatusOK, map[string]any{"coupon": coupon})
+}
+
+func (s *Server) listCoupons(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	coupons, meta := paginate(r, s.coupons.list(nil))
+	writeJSON(w, http.StatusOK, &lago.CouponList{Coupons: coupons, Meta: meta})
+}
+
+func (s *Server) getCoupon(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	coupon, ok := s.coupons.get(r.PathValue("code"))
+	if !ok {
+		writeNotFound(w, "coupon")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"coupon": coupon})
+}
+
+func (s *Server) deleteCoupon(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	coupon, ok := s.coupons.remove(r.PathValue("code"))
+	if !ok {
+		writeNotFound(w, "coupon")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"coupon": coupon})
+}
+
+func (s *Server) applyCoupon(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		AppliedCoupon *lago.ApplyCouponInput `json:"applied_coupon"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	in := params.AppliedCoupon
+	if in == nil {
+		writeValidationError(w, "applied_coupon", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, ok := s.customers.get(in.ExternalCustomerID)
+	if !ok {
+		writeNotFound(w, "customer")
+		return
+	}
+	coupon, ok := s.coupons.get(in.CouponCode)
+	if !ok {
+		writeNotFound(w, "coupon")
+		return
+	}
+
+	applied := &lago.AppliedCoupon{
+		LagoID:             uuid.New(),
+		LagoCouponID:       coupon.LagoID,
+		ExternalCustomerID: customer.ExternalID,
+		LagoCustomerID:     customer.LagoID,
+		Status:             lago.AppliedCouponStatusActive,
+		CouponName:         coupon.Name,
+		CouponCode:         coupon.Code,
+		AmountCents:        cmp.Or(in.AmountCents, coupon.AmountCents),
+		AmountCurrency:     cmp.Or(in.AmountCurrency, coupon.AmountCurrency),
+		PercentageRate:     cmp.Or(in.PercentageRate, coupon.PercentageRate),
+		Frequency:          cmp.Or(in.Frequency, coupon.Frequency),
+		FrequencyDuration:  cmp.Or(in.FrequencyDuration, coupon.FrequencyDuration),
+	}
+	applied.AmountCentsRemaining = applied.AmountCents
+	applied.FrequencyDurationRemaining = applied.FrequencyDuration
+
+	s.appliedCoupons.put(applied.LagoID.String(), applied)
+	writeJSON(w, http.StatusOK, map[string]any{"applied_coupon": applied})
+}
+
+func (s *Server) listAppliedCoupons(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	externalCustomerID := q.Get("external_customer_id")
+	status := q.Get("status")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	applied := s.appliedCoupons.list(func(ac *lago.AppliedCoupon) bool {
+		if externalCustomerID != "" && ac.ExternalCustomerID != externalCustomerID {
+			return false
+		}
+		return status == "" || string(ac.Status) == status
+	})
+
+	applied, meta := paginate(r, applied)
+	writeJSON(w, http.StatusOK, &lago.AppliedCouponList{AppliedCoupons: applied, Meta: meta})
+}
+
+func (s *Server) deleteAppliedCoupon(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	applied, ok := s.appliedCoupons.get(r.PathValue("id"))
+	if !ok || applied.ExternalCustomerID != r.PathValue("external_id") {
+		writeNotFound(w, "applied_coupon")
+		return
+	}
+
+	applied.Status = lago.AppliedCouponStatusTerminated
+	applied.TerminatedAt = time.Now().UTC()
+	writeJSON(w, http.StatusOK, map[string]any{"applied_coupon": applied})
+}
+
+// Invoices
+
+// createInvoice creates a finalized one-off invoice.
+func (s *Server) createInvoice(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Invoice *lago.InvoiceOneOffInput `json:"invoice"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	in := params.Invoice
+	if in == nil || len(in.Fees) == 0 {
+		writeValidationError(w, "fees", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, ok := s.customers.get(in.ExternalCustomerId)
+	if !ok {
+		writeNotFound(w, "customer")
+		return
+	}
+
+	currency := lago.Currency(in.Currency)
+	if currency == "" {
+		currency = customer.Currency
+	}
+
+	invoice := &lago.Invoice{
+		LagoID:            uuid.New(),
+		SequentialID:      len(s.invoices.keys) + 1,
+		IssuingDate:       time.Now().UTC().Format(time.DateOnly),
+		InvoiceType:       lago.OneOffInvoiceType,
+		Status:            lago.InvoiceStatusFinalized,
+		PaymentStatus:     lago.InvoicePaymentStatusPending,
+		Currency:          currency,
+		BillingEntityCode: cmp.Or(in.BillingEntityCode, customer.BillingEntityCode),
+		Customer:          customer,
+	}
+	invoice.Number = "LAGO-" + strconv.Itoa(invoice.SequentialID)
+
+	for _, fi := range in.Fees {
+		units := fi.Units
+		if units == 0 {
+			units = 1
+		}
+		amount := int(float32(fi.UnitAmountCents) * units)
+		invoice.FeesAmountCents += amount
+		invoice.Fees = append(invoice.Fees, &lago.Fee{
+			LagoID:         uuid.New(),
+			LagoInvoiceID:  invoice.LagoID,
+			AmountCents:    amount,
+			AmountCurrency: string(currency),
+			Units:          strconv.FormatFloat(float64(units), 'f', -1, 32),
+		})
+	}
+	invoice.SubTotalExcludingTaxesAmountCents = invoice.FeesAmountCents
+	invoice.SubTotalIncludingTaxesAmountCents = invoice.FeesAmountCents
+	invoice.TotalAmountCents = invoice.FeesAmountCents
+
+	s.invoices.put(invoice.LagoID.String(), invoice)
+	writeJSON(w, http.StatusOK, map[string]any{"invoice": invoice})
+}
+
+func (s *Server) updateInvoice(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Invoice *lago.InvoiceInput `json:"invoice"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, ok := s.invoices.get(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, "invoice")
+		return
+	}
+
+	if in := params.Invoice; in != nil {
+		if in.PaymentStatus != "" {
+			invoice.PaymentStatus = in.PaymentStatus
+		}
+		for _, m := range in.Metadata {
+			invoice.Metadata = append(invoice.Metadata, &lago.InvoiceMetadataResponse{
+				LagoID:    uuid.New(),
+				Key:       m.Key,
+				Value:     m.Value,
+				CreatedAt: time.Now().UTC(),
+			})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"invoice": invoice})
+}
+
+func (s *Server) finalizeInvoice(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, ok := s.invoices.get(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, "invoice")
+		return
+	}
+	if invoice.Status != lago.InvoiceStatusDraft {
+		writeError(w, http.StatusMethodNotAllowed, "is_not_draft", nil)
+		return
+	}
+
+	invoice.Status = lago.InvoiceStatusFinalized
+	writeJSON(w, http.StatusOK, map[string]any{"invoice": invoice})
+}
+
+func (s *Server) listInvoices(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	externalCustomerID := q.Get("external_customer_id")
+	status := q.Get("status")
+	paymentStatus := q.Get("payment_status")
+	billingEntityCodes := q["billing_entity_codes[]"]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoices := s.invoices.list(func(invoice *lago.Invoice) bool {
+		if externalCustomerID != "" && (invoice.Customer == nil || invoice.Customer.ExternalID != externalCustomerID) {
+			return false
+		}
+		if status != "" && string(invoice.Status) != status {
+			return false
+		}
+		if len(billingEntityCodes) > 0 && !slices.Contains(billingEntityCodes, invoice.BillingEntityCode) {
+			return false
+		}
+		return paymentStatus == "" || string(invoice.PaymentStatus) == paymentStatus
+	})
+
+	invoices, meta := paginate(r, invoices)
+	writeJSON(w, http.StatusOK, &lago.InvoiceList{Invoices: invoices, Meta: meta})
+}
+
+func (s *Server) getInvoice(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, ok := s.invoices.get(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, "invoice")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"invoice": invoice})
+}
+
+// Webhook endpoints
+
+func (s *Server) createWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		WebhookEndpoint *lago.WebhookEndpointInput `json:"webhook_endpoint"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+	in := params.WebhookEndpoint
+	if in == nil || in.WebhookURL == "" {
+		writeValidationError(w, "webhook_url", "value_is_mandatory")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	algo := in.SignatureAlgo
+	if algo == "" {
+		algo = lago.JWT
+	}
+
+	endpoint := &lago.WebhookEndpoint{
+		LagoID:        uuid.New(),
+		WebhookURL:    in.WebhookURL,
+		SignatureAlgo: algo,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	s.webhookEndpoints.put(endpoint.LagoID.String(), endpoint)
+	writeJSON(w, http.StatusOK, map[string]any{"webhook_endpoint": endpoint})
+}
+
+func (s *Server) updateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		WebhookEndpoint *lago.WebhookEndpointInput `json:"webhook_endpoint"`
+	}
+	if !decode(w, r, &params) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoint, ok := s.webhookEndpoints.get(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, "webhook_endpoint")
+		return
+	}
+
+	if in := params.WebhookEndpoint; in != nil {
+		if in.WebhookURL != "" {
+			endpoint.WebhookURL = in.WebhookURL
+		}
+		if in.SignatureAlgo != "" {
+			endpoint.SignatureAlgo = in.SignatureAlgo
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"webhook_endpoint": endpoint})
+}
+
+func (s *Server) listWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoints, meta := paginate(r, s.webhookEndpoints.list(nil))
+	writeJSON(w, http.StatusOK, &lago.WebhookEndpointList{WebhookEndpoints: endpoints, Meta: meta})
+}
+
+func (s *Server) getWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoint, ok := s.webhookEndpoints.get(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, "webhook_endpoint")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"webhook_endpoint": endpoint})
+}
+
+func (s *Server) deleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoint, ok := s.webhookEndpoints.remove(r.PathValue("id"))
+	if !ok {
+		writeNotFound(w, "webhook_endpoint")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"webhook_endpoint": endpoint})
+}
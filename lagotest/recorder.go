@@ -0,0 +1,287 @@
+package lagotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+
+	lago "github.com/nikola-jokic/lago-go"
+	"github.com/nikola-jokic/lago-go/internal/redact"
+)
+
+// RecorderMode selects whether a Recorder talks to a real server.
+type RecorderMode int
+
+const (
+	// ModeReplay serves the requests from the cassette, without network
+	// access. It is the zero value, so tests replay unless told otherwise.
+	ModeReplay RecorderMode = iota
+	// ModeRecord sends the requests with RecorderConfig.Client and writes
+	// every interaction to the cassette on Close, replacing its content.
+	ModeRecord
+)
+
+// ErrInteractionNotFound is returned in replay mode when the cassette has no
+// unused interaction matching the request.
+var ErrInteractionNotFound = errors.New("lagotest: no recorded interaction matches the request")
+
+const scrubbed = "[SCRUBBED]"
+
+// RecorderConfig configures a Recorder.
+type RecorderConfig struct {
+	// Cassette is the path of the cassette file.
+	Cassette string
+	// Mode is the recorder mode. Defaults to ModeReplay.
+	Mode RecorderMode
+	// Client sends the requests in record mode.
+	// Defaults to http.DefaultClient.
+	Client lago.HTTPClient
+	// ScrubFields are the JSON fields whose values are replaced, at any
+	// depth, in the recorded request and response bodies, and the query
+	// parameters whose values are replaced in the recorded requests. The
+	// Authorization header is always scrubbed.
+	ScrubFields []string
+}
+
+// Recorder is a lago.HTTPClient recording request and response pairs to a
+// cassette file, and replaying them deterministically.
+//
+// Requests are matched on the method, the path, the scrubbed query with
+// sorted keys and values, and the scrubbed body, with JSON bodies compared regardless of
+// formatting and key order. Each recorded interaction is replayed once, in
+// the recorded order.
+//
+// It is safe for concurrent use by multiple goroutines.
+type Recorder struct {
+	cassette    string
+	mode        RecorderMode
+	client      lago.HTTPClient
+	scrubFields map[string]bool
+
+	mu           sync.Mutex
+	interactions []*interaction
+	used         []bool
+}
+
+type cassette struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// NewRecorder returns a recorder for the cassette. In replay mode, the
+// cassette is loaded and must exist.
+func NewRecorder(cfg RecorderConfig) (*Recorder, error) {
+	if cfg.Cassette == "" {
+		return nil, errors.New("lagotest: cassette path is required")
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	r := &Recorder{
+		cassette:    cfg.Cassette,
+		mode:        cfg.Mode,
+		client:      client,
+		scrubFields: make(map[string]bool, len(cfg.ScrubFields)),
+	}
+	for _, f := range cfg.ScrubFields {
+		r.scrubFields[f] = true
+	}
+
+	switch cfg.Mode {
+	case ModeReplay:
+		b, err := os.ReadFile(cfg.Cassette)
+		if err != nil {
+			return nil, fmt.Errorf("lagotest: failed to read cassette: %w", err)
+		}
+
+		var c cassette
+		if err := json.Unmarshal(b, &c); err != nil {
+			return nil, fmt.Errorf("lagotest: failed to decode cassette %s: %w", cfg.Cassette, err)
+		}
+		r.interactions = c.Interactions
+		r.used = make([]bool, len(c.Interactions))
+	case ModeRecord:
+	default:
+		return nil, fmt.Errorf("lagotest: unknown recorder mode %d", cfg.Mode)
+	}
+
+	return r, nil
+}
+
+// Do records or replays the request, depending on the recorder mode.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	recorded := recordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  normalizeQuery(redact.Query(req.URL.Query(), r.scrubFields, scrubbed)),
+		Header: r.scrubHeader(req.Header),
+		Body:   r.scrubBody(body),
+	}
+
+	if r.mode == ModeRecord {
+		return r.record(req, recorded)
+	}
+	return r.replay(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	// The length of the scrubbed body differs, and is set on replay.
+	header := res.Header.Clone()
+	header.Del("Content-Length")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, &interaction{
+		Request: recorded,
+		Response: recordedResponse{
+			Status: res.StatusCode,
+			Header: header,
+			Body:   r.scrubBody(body),
+		},
+	})
+
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.interactions {
+		if r.used[i] || !in.Request.matches(&recorded) {
+			continue
+		}
+		r.used[i] = true
+
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		header.Set("Content-Length", strconv.Itoa(len(in.Response.Body)))
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewBufferString(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, req.Method, req.URL.Redacted())
+}
+
+// Close writes the recorded interactions to the cassette in record mode.
+// It does nothing in replay mode.
+func (r *Recorder) Close() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(&cassette{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.cassette), 0o755); err != nil {
+		return fmt.Errorf("lagotest: failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.cassette, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("lagotest: failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+func (rr *recordedRequest) matches(other *recordedRequest) bool {
+	return rr.Method == other.Method &&
+		rr.Path == other.Path &&
+		rr.Query == other.Query &&
+		rr.Body == other.Body
+}
+
+func (r *Recorder) scrubHeader(h http.Header) http.Header {
+	h = h.Clone()
+	if h.Get("Authorization") != "" {
+		h.Set("Authorization", scrubbed)
+	}
+	return h
+}
+
+// scrubBody replaces the values of the scrubbed fields and re-encodes JSON
+// bodies with sorted keys, so they can be compared. Bodies that are not JSON
+// are kept as they are.
+func (r *Recorder) scrubBody(body []byte) string {
+	if b, ok := redact.JSON(body, r.scrubFields, scrubbed); ok {
+		return string(b)
+	}
+	return string(body)
+}
+
+// normalizeQuery encodes the query with its keys and values sorted.
+func normalizeQuery(q url.Values) string {
+	for _, values := range q {
+		slices.Sort(values)
+	}
+	return q.Encode()
+}
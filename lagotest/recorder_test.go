@@ -0,0 +1,234 @@
+package lagotest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	lago "github.com/nikola-jokic/lago-go"
+)
+
+func newRecordingClient(t *testing.T, rec *Recorder) *lago.Client {
+	t.Helper()
+
+	c, err := lago.New(lago.Config{
+		BaseURL: "http://lago.test",
+		APIKey:  DefaultAPIKey,
+		Client:  rec,
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	return c
+}
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	cassette := filepath.Join(t.TempDir(), "cassettes", "customers.json")
+
+	srv := NewServer()
+	rec, err := NewRecorder(RecorderConfig{
+		Cassette:    cassette,
+		Mode:        ModeRecord,
+		Client:      srv.HTTPClient(),
+		ScrubFields: []string{"email"},
+	})
+	if err != nil {
+		t.Fatalf("NewRecorder() = %v", err)
+	}
+
+	// The recorder sends the requests to the fake server, wherever the
+	// client points.
+	recording, err := lago.New(lago.Config{BaseURL: srv.URL, APIKey: srv.APIKey, Client: rec})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	input := &lago.CustomerInput{ExternalID: "cus", Name: "Recorded", Email: "jane@example.com"}
+	if _, err := recording.CreateCustomer(ctx, input); err != nil {
+		t.Fatalf("CreateCustomer() = %v", err)
+	}
+	if _, err := recording.ListCustomers(ctx, &lago.CustomerListInput{PerPage: 10, Page: 1}); err != nil {
+		t.Fatalf("ListCustomers() = %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	srv.Close()
+
+	b, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	for _, secret := range []string{DefaultAPIKey, "jane@example.com"} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	rec, err = NewRecorder(RecorderConfig{Cassette: cassette, ScrubFields: []string{"email"}})
+	if err != nil {
+		t.Fatalf("NewRecorder() = %v", err)
+	}
+	c := newRecordingClient(t, rec)
+
+	// The body matches regardless of the scrubbed values, and the query
+	// regardless of the parameter order.
+	input.Email = "john@example.com"
+	customer, err := c.CreateCustomer(ctx, input)
+	if err != nil {
+		t.Fatalf("CreateCustomer() = %v", err)
+	}
+	if customer.Name != "Recorded" {
+		t.Errorf("CreateCustomer() name = %q, want %q", customer.Name, "Recorded")
+	}
+
+	list, err := c.ListCustomers(ctx, &lago.CustomerListInput{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("ListCustomers() = %v", err)
+	}
+	if len(list.Customers) != 1 {
+		t.Errorf("ListCustomers() returned %d customers, want 1", len(list.Customers))
+	}
+
+	if _, err := c.ListCustomers(ctx, &lago.CustomerListInput{Page: 1, PerPage: 10}); !errors.Is(err, ErrInteractionNotFound) {
+		t.Errorf("ListCustomers() replayed twice = %v, want %v", err, ErrInteractionNotFound)
+	}
+}
+
+func TestRecorderMissingCassette(t *testing.T) {
+	_, err := NewRecorder(RecorderConfig{Cassette: filepath.Join(t.TempDir(), "missing.json")})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("NewRecorder() = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+type httpClientFunc func(req *http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRecorderScrubbedResponse(t *testing.T) {
+	ctx := context.Background()
+	cassette := filepath.Join(t.TempDir(), "customer.json")
+
+	const body = `{"customer":{"external_id":"cus","email":"jane@example.com","net_payment_term":9007199254740993}}`
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		res := &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        make(http.Header),
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}
+		res.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		return res, nil
+	})
+
+	rec, err := NewRecorder(RecorderConfig{
+		Cassette:    cassette,
+		Mode:        ModeRecord,
+		Client:      client,
+		ScrubFields: []string{"email"},
+	})
+	if err != nil {
+		t.Fatalf("NewRecorder() = %v", err)
+	}
+	if _, err := newRecordingClient(t, rec).GetCustomer(ctx, "cus"); err != nil {
+		t.Fatalf("GetCustomer() = %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	rec, err = NewRecorder(RecorderConfig{Cassette: cassette})
+	if err != nil {
+		t.Fatalf("NewRecorder() = %v", err)
+	}
+	req, err := http.NewRequest(http.MethodGet, "http://lago.test/api/v1/customers/cus", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() = %v", err)
+	}
+	res, err := rec.Do(req)
+	if err != nil {
+		t.Fatalf("Do() = %v", err)
+	}
+	defer res.Body.Close()
+
+	got, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if !strings.Contains(string(got), `"net_payment_term":9007199254740993`) {
+		t.Errorf("body = %s, want the number as recorded", got)
+	}
+	if strings.Contains(string(got), "jane@example.com") {
+		t.Errorf("body = %s, want the email scrubbed", got)
+	}
+	if cl := res.Header.Get("Content-Length"); cl != strconv.Itoa(len(got)) {
+		t.Errorf("Content-Length = %s, want %d", cl, len(got))
+	}
+}
+
+func TestRecorderScrubbedQuery(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "invoices.json")
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"invoices":[],"meta":{}}`)),
+			Request:    req,
+		}, nil
+	})
+
+	do := func(rec *Recorder, customer string) error {
+		req, err := http.NewRequest(http.MethodGet, "http://lago.test/api/v1/invoices?page=1&external_customer_id="+customer, nil)
+		if err != nil {
+			t.Fatalf("NewRequest() = %v", err)
+		}
+		res, err := rec.Do(req)
+		if err == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+
+	rec, err := NewRecorder(RecorderConfig{
+		Cassette:    cassette,
+		Mode:        ModeRecord,
+		Client:      client,
+		ScrubFields: []string{"external_customer_id"},
+	})
+	if err != nil {
+		t.Fatalf("NewRecorder() = %v", err)
+	}
+	if err := do(rec, "cus_secret"); err != nil {
+		t.Fatalf("Do() = %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	b, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	if strings.Contains(string(b), "cus_secret") {
+		t.Errorf("cassette contains the scrubbed query parameter: %s", b)
+	}
+
+	rec, err = NewRecorder(RecorderConfig{Cassette: cassette, ScrubFields: []string{"external_customer_id"}})
+	if err != nil {
+		t.Fatalf("NewRecorder() = %v", err)
+	}
+	if err := do(rec, "cus_other"); err != nil {
+		t.Errorf("Do() with another scrubbed value = %v", err)
+	}
+}
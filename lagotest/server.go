@@ -0,0 +1,337 @@
+// Package lagotest provides an in-memory fake of the Lago API for testing
+// code that uses *lago.Client without a live Lago instance.
+//
+// The server implements the customers, plans, billable metrics,
+// subscriptions, events, wallets, coupons, invoices and webhook endpoints
+// APIs, using the JSON shapes of the lago package. List endpoints are
+// paginated, and failures can be injected with Server.Fail.
+//
+//	srv := lagotest.NewServer()
+//	defer srv.Close()
+//
+//	client := srv.Client()
+//
+// Recorder records the requests sent to a real Lago instance to a cassette
+// file, and replays them later without network access.
+package lagotest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	lago "github.com/nikola-jokic/lago-go"
+)
+
+// DefaultAPIKey is the API key accepted by a new server.
+const DefaultAPIKey = "lagotest-api-key"
+
+// defaultPerPage is the page size used when the request does not set one.
+const defaultPerPage = 20
+
+// Server is an in-memory Lago API served over HTTP.
+// It is safe for concurrent use by multiple goroutines.
+type Server struct {
+	// URL is the base URL of the server, to be used as lago.Config.BaseURL.
+	URL string
+	// APIKey is the bearer token the server accepts.
+	APIKey string
+
+	srv *httptest.Server
+
+	mu               sync.Mutex
+	failures         []*Failure
+	customers        *store[lago.Customer]
+	plans            *store[lago.Plan]
+	billableMetrics  *store[lago.BillableMetric]
+	subscriptions    *store[lago.Subscription]
+	events           *store[lago.Event]
+	wallets          *store[lago.Wallet]
+	coupons          *store[lago.Coupon]
+	appliedCoupons   *store[lago.AppliedCoupon]
+	invoices         *store[lago.Invoice]
+	webhookEndpoints *store[lago.WebhookEndpoint]
+}
+
+// NewServer starts a server accepting DefaultAPIKey.
+// Close must be called to stop it.
+func NewServer() *Server {
+	s := &Server{
+		APIKey:           DefaultAPIKey,
+		customers:        newStore[lago.Customer](),
+		plans:            newStore[lago.Plan](),
+		billableMetrics:  newStore[lago.BillableMetric](),
+		subscriptions:    newStore[lago.Subscription](),
+		events:           newStore[lago.Event](),
+		wallets:          newStore[lago.Wallet](),
+		coupons:          newStore[lago.Coupon](),
+		appliedCoupons:   newStore[lago.AppliedCoupon](),
+		invoices:         newStore[lago.Invoice](),
+		webhookEndpoints: newStore[lago.WebhookEndpoint](),
+	}
+
+	mux := http.NewServeMux()
+	s.routes(mux)
+
+	s.srv = httptest.NewServer(s.handler(mux))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// HTTPClient returns an HTTP client configured for the server.
+func (s *Server) HTTPClient() *http.Client {
+	return s.srv.Client()
+}
+
+// Client returns a lago client talking to the server.
+func (s *Server) Client() *lago.Client {
+	c, err := lago.New(lago.Config{
+		BaseURL: s.URL,
+		APIKey:  s.APIKey,
+		Client:  s.HTTPClient(),
+	})
+	if err != nil {
+		// The configuration is built from a running server, so it is valid.
+		panic(err)
+	}
+	return c
+}
+
+// Failure is an error response injected by Server.Fail.
+type Failure struct {
+	// Method matches the request method. An empty method matches any.
+	Method string
+	// Path matches the request path relative to /api/v1/, such as
+	// "customers" or "events/batch". An empty path matches any.
+	Path string
+	// Status is the response status code.
+	Status int
+	// Code is the error code of the response body.
+	Code string
+	// Details are the error details of the response body, encoded as they
+	// are: a map[string][]string for an error of the whole request, or a map
+	// keyed by the index of the event for the errors of a batch.
+	Details any
+	// Times is the number of requests that fail. Zero fails one request,
+	// and a negative value fails every request until Reset is called.
+	Times int
+}
+
+// Fail makes the matching requests fail with the failure's response.
+// Failures are matched in the order they were added.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Times == 0 {
+		f.Times = 1
+	}
+	s.failures = append(s.failures, &f)
+}
+
+// Reset removes every injected failure and stored resource.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = nil
+	s.customers.reset()
+	s.plans.reset()
+	s.billableMetrics.reset()
+	s.subscriptions.reset()
+	s.events.reset()
+	s.wallets.reset()
+	s.coupons.reset()
+	s.appliedCoupons.reset()
+	s.invoices.reset()
+	s.webhookEndpoints.reset()
+}
+
+// Events returns the events received by the server, in order.
+func (s *Server) Events() []*lago.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events.list(nil)
+}
+
+// AddInvoice stores the invoice, for example to test code reading invoices
+// generated by subscriptions.
+func (s *Server) AddInvoice(invoice *lago.Invoice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invoices.put(invoice.LagoID.String(), invoice)
+}
+
+// handler authenticates the request and applies the injected failures
+// before serving it.
+func (s *Server) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.APIKey {
+			writeError(w, http.StatusUnauthorized, "unauthorized", nil)
+			return
+		}
+
+		if f := s.failure(r); f != nil {
+			writeError(w, f.Status, f.Code, f.Details)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) failure(r *http.Request) *Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, lago.ApiV1Path)
+	for i, f := range s.failures {
+		if (f.Method != "" && f.Method != r.Method) || (f.Path != "" && f.Path != path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// store keeps resources by key, in insertion order.
+type store[T any] struct {
+	keys  []string
+	items map[string]*T
+}
+
+func newStore[T any]() *store[T] {
+	return &store[T]{items: make(map[string]*T)}
+}
+
+func (st *store[T]) get(key string) (*T, bool) {
+	v, ok := st.items[key]
+	return v, ok
+}
+
+func (st *store[T]) put(key string, v *T) {
+	if _, ok := st.items[key]; !ok {
+		st.keys = append(st.keys, key)
+	}
+	st.items[key] = v
+}
+
+func (st *store[T]) remove(key string) (*T, bool) {
+	v, ok := st.items[key]
+	if !ok {
+		return nil, false
+	}
+	delete(st.items, key)
+	for i, k := range st.keys {
+		if k == key {
+			st.keys = append(st.keys[:i], st.keys[i+1:]...)
+			break
+		}
+	}
+	return v, true
+}
+
+// list returns the resources matching the filter. A nil filter matches all.
+func (st *store[T]) list(filter func(*T) bool) []*T {
+	var items []*T
+	for _, k := range st.keys {
+		v := st.items[k]
+		if filter == nil || filter(v) {
+			items = append(items, v)
+		}
+	}
+	return items
+}
+
+func (st *store[T]) reset() {
+	st.keys = nil
+	st.items = make(map[string]*T)
+}
+
+// paginate returns the page requested by the page and per_page query
+// parameters.
+func paginate[T any](r *http.Request, items []*T) ([]*T, lago.Metadata) {
+	q := r.URL.Query()
+
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	totalPages := (len(items) + perPage - 1) / perPage
+	meta := lago.Metadata{
+		CurrentPage: page,
+		TotalPages:  totalPages,
+		TotalCount:  len(items),
+	}
+	if page < totalPages {
+		meta.NextPage = page + 1
+	}
+	if page > 1 {
+		meta.PrevPage = page - 1
+	}
+
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+	return items[start:end], meta
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+type errorBody struct {
+	Status  int    `json:"status"`
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+	Details any    `json:"error_details,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, status int, code string, details any) {
+	writeJSON(w, status, &errorBody{
+		Status:  status,
+		Error:   http.StatusText(status),
+		Code:    code,
+		Details: details,
+	})
+}
+
+func writeNotFound(w http.ResponseWriter, resource string) {
+	writeError(w, http.StatusNotFound, resource+"_not_found", nil)
+}
+
+func writeValidationError(w http.ResponseWriter, field string, codes ...string) {
+	writeError(w, http.StatusUnprocessableEntity, "validation_errors", map[string][]string{
+		field: codes,
+	})
+}
+
+// decode reads the JSON body of the request, responding with 400 when it
+// is invalid.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", nil)
+		return false
+	}
+	return true
+}
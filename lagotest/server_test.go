@@ -0,0 +1,220 @@
+package lagotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	lago "github.com/nikola-jokic/lago-go"
+)
+
+func TestServerCustomers(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := srv.Client()
+
+	for i := range 5 {
+		_, err := c.CreateCustomer(ctx, &lago.CustomerInput{
+			ExternalID: "cus-" + strconv.Itoa(i),
+			Name:       "Customer",
+		})
+		if err != nil {
+			t.Fatalf("CreateCustomer() = %v", err)
+		}
+	}
+
+	// Creating an existing customer updates it.
+	if _, err := c.CreateCustomer(ctx, &lago.CustomerInput{ExternalID: "cus-0", Name: "Updated"}); err != nil {
+		t.Fatalf("CreateCustomer() = %v", err)
+	}
+
+	customer, err := c.GetCustomer(ctx, "cus-0")
+	if err != nil {
+		t.Fatalf("GetCustomer() = %v", err)
+	}
+	if customer.Name != "Updated" {
+		t.Errorf("GetCustomer() name = %q, want %q", customer.Name, "Updated")
+	}
+
+	customers, err := lago.CollectAll[lago.Customer](ctx, c.ListCustomers, &lago.CustomerListInput{PerPage: 2})
+	if err != nil {
+		t.Fatalf("CollectAll() = %v", err)
+	}
+	if len(customers) != 5 {
+		t.Errorf("CollectAll() returned %d customers, want 5", len(customers))
+	}
+
+	if _, err := c.DeleteCustomer(ctx, "cus-0"); err != nil {
+		t.Fatalf("DeleteCustomer() = %v", err)
+	}
+	if _, err := c.GetCustomer(ctx, "cus-0"); !errors.Is(err, lago.ErrNotFound) {
+		t.Errorf("GetCustomer() = %v, want %v", err, lago.ErrNotFound)
+	}
+}
+
+func TestServerSubscriptions(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := srv.Client()
+
+	input := &lago.SubscriptionInput{ExternalCustomerID: "cus", PlanCode: "basic", ExternalID: "sub"}
+	if _, err := c.CreateSubscription(ctx, input); !errors.Is(err, lago.ErrNotFound) {
+		t.Fatalf("CreateSubscription() without customer = %v, want %v", err, lago.ErrNotFound)
+	}
+
+	if _, err := c.CreateCustomer(ctx, &lago.CustomerInput{ExternalID: "cus"}); err != nil {
+		t.Fatalf("CreateCustomer() = %v", err)
+	}
+	if _, err := c.CreatePlan(ctx, &lago.PlanInput{Code: "basic", Interval: lago.PlanMonthly}); err != nil {
+		t.Fatalf("CreatePlan() = %v", err)
+	}
+	if _, err := c.CreateSubscription(ctx, input); err != nil {
+		t.Fatalf("CreateSubscription() = %v", err)
+	}
+	if _, err := c.TerminateSubscription(ctx, &lago.SubscriptionTerminateInput{ExternalID: "sub"}); err != nil {
+		t.Fatalf("TerminateSubscription() = %v", err)
+	}
+
+	tests := map[string]struct {
+		input *lago.SubscriptionListInput
+		want  int
+	}{
+		"default": {
+			input: &lago.SubscriptionListInput{},
+			want:  0,
+		},
+		"terminated": {
+			input: &lago.SubscriptionListInput{Status: []lago.SubscriptionStatus{lago.SubscriptionStatusTerminated}},
+			want:  1,
+		},
+		"other customer": {
+			input: &lago.SubscriptionListInput{
+				ExternalCustomerID: "other",
+				Status:             []lago.SubscriptionStatus{lago.SubscriptionStatusTerminated},
+			},
+			want: 0,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			list, err := c.ListSubscriptions(ctx, tt.input)
+			if err != nil {
+				t.Fatalf("ListSubscriptions() = %v", err)
+			}
+			if len(list.Subscriptions) != tt.want {
+				t.Errorf("ListSubscriptions() returned %d subscriptions, want %d", len(list.Subscriptions), tt.want)
+			}
+		})
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := srv.Client()
+
+	if _, err := c.CreateEvent(ctx, &lago.EventInput{TransactionID: "tx-1", Code: "calls"}); err != nil {
+		t.Fatalf("CreateEvent() = %v", err)
+	}
+
+	_, err := c.CreateEvent(ctx, &lago.EventInput{TransactionID: "tx-1", Code: "calls"})
+	if !errors.Is(err, lago.ErrValidation) {
+		t.Fatalf("CreateEvent() duplicate = %v, want %v", err, lago.ErrValidation)
+	}
+
+	batch := []*lago.EventInput{
+		{TransactionID: "tx-2", Code: "calls"},
+		{TransactionID: "tx-1", Code: "calls"},
+		{Code: "calls"},
+	}
+	_, err = c.BatchEvents(ctx, &batch)
+	accepted, rejected := lago.SplitBatchEvents(batch, err)
+	if len(accepted) != 1 || len(rejected) != 2 {
+		t.Fatalf("SplitBatchEvents() = %d accepted, %d rejected, want 1 and 2", len(accepted), len(rejected))
+	}
+	if len(srv.Events()) != 1 {
+		t.Errorf("Events() = %d events, want 1 after a rejected batch", len(srv.Events()))
+	}
+
+	if _, err := c.BatchEvents(ctx, &accepted); err != nil {
+		t.Fatalf("BatchEvents() = %v", err)
+	}
+	if _, err := c.GetEvent(ctx, "tx-2"); err != nil {
+		t.Errorf("GetEvent() = %v", err)
+	}
+
+	tooMany := make([]*lago.EventInput, lago.MaxBatchEvents+1)
+	for i := range tooMany {
+		tooMany[i] = &lago.EventInput{TransactionID: fmt.Sprintf("tx-many-%d", i), Code: "calls"}
+	}
+	_, err = c.BatchEvents(ctx, &tooMany)
+	accepted, rejected = lago.SplitBatchEvents(tooMany, err)
+	if len(accepted) != 0 || len(rejected) != len(tooMany) {
+		t.Errorf("SplitBatchEvents() too many = %d accepted, %d rejected, want 0 and %d", len(accepted), len(rejected), len(tooMany))
+	}
+}
+
+func TestServerFail(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := srv.Client()
+
+	srv.Fail(Failure{Method: http.MethodGet, Path: "customers", Status: http.StatusInternalServerError, Code: "internal_error", Times: 2})
+
+	for range 2 {
+		if _, err := c.ListCustomers(ctx, &lago.CustomerListInput{}); !errors.Is(err, lago.ErrServer) {
+			t.Fatalf("ListCustomers() = %v, want %v", err, lago.ErrServer)
+		}
+	}
+	if _, err := c.ListCustomers(ctx, &lago.CustomerListInput{}); err != nil {
+		t.Fatalf("ListCustomers() after failures = %v", err)
+	}
+
+	unauthorized, err := lago.New(lago.Config{BaseURL: srv.URL, APIKey: "invalid", Client: srv.HTTPClient()})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if _, err := unauthorized.ListCustomers(ctx, &lago.CustomerListInput{}); !errors.Is(err, lago.ErrUnauthorized) {
+		t.Errorf("ListCustomers() with wrong key = %v, want %v", err, lago.ErrUnauthorized)
+	}
+
+	srv.Fail(Failure{
+		Method:  http.MethodPost,
+		Path:    "events/batch",
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "validation_errors",
+		Details: map[string][]string{"events": {"too_many_events"}},
+	})
+	batch := []*lago.EventInput{
+		{TransactionID: "tx-1", Code: "calls"},
+		{TransactionID: "tx-2", Code: "calls"},
+	}
+	_, err = c.BatchEvents(ctx, &batch)
+	if accepted, rejected := lago.SplitBatchEvents(batch, err); len(accepted) != 0 || len(rejected) != len(batch) {
+		t.Errorf("SplitBatchEvents() batch failure = %d accepted, %d rejected, want 0 and %d", len(accepted), len(rejected), len(batch))
+	}
+
+	srv.Fail(Failure{
+		Method:  http.MethodPost,
+		Path:    "events/batch",
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "validation_errors",
+		Details: map[string]map[string][]string{"1": {"code": {"value_is_invalid"}}},
+	})
+	_, err = c.BatchEvents(ctx, &batch)
+	if accepted, rejected := lago.SplitBatchEvents(batch, err); len(accepted) != 1 || len(rejected) != 1 {
+		t.Errorf("SplitBatchEvents() event failure = %d accepted, %d rejected, want 1 and 1", len(accepted), len(rejected))
+	}
+}
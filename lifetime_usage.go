@@ -42,7 +42,7 @@ type LifetimeUsageInput struct {
 }
 
 func (c *Client) GetLifetimeUsage(ctx context.Context, externalSubscriptionID string) (*LifetimeUsage, error) {
-	u := c.url("subscriptions/"+externalSubscriptionID+"/lifetime_usage", nil)
+	u := c.url("GetLifetimeUsage", "subscriptions/{external_id}/lifetime_usage", nil, externalSubscriptionID)
 	result, err := get[lifetimeUsageResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -52,7 +52,7 @@ func (c *Client) GetLifetimeUsage(ctx context.Context, externalSubscriptionID st
 }
 
 func (c *Client) UpdateLifetimeUsage(ctx context.Context, lifetimeUsageInput *LifetimeUsageInput) (*LifetimeUsage, error) {
-	u := c.url("subscriptions/"+lifetimeUsageInput.ExternalSubscriptionID+"/lifetime_usage", nil)
+	u := c.url("UpdateLifetimeUsage", "subscriptions/{external_id}/lifetime_usage", nil, lifetimeUsageInput.ExternalSubscriptionID)
 	result, err := put[lifetimeUsageParams, lifetimeUsageResult](
 		ctx,
 		c,
@@ -36,6 +36,6 @@ type Mrr struct {
 }
 
 func (c *Client) ListMrrs(ctx context.Context, MrrListInput *MrrListInput) (*MrrList, error) {
-	u := c.url("analytics/mrr", MrrListInput.query())
+	u := c.url("ListMrrs", "analytics/mrr", MrrListInput.query())
 	return get[MrrList](ctx, c, u)
 }
@@ -0,0 +1,159 @@
+package lago
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Environment variables read by WithEnv.
+const (
+	EnvAPIKey = "LAGO_API_KEY"
+	EnvAPIURL = "LAGO_API_URL"
+)
+
+// DefaultTimeout bounds the calls of a client created with NewWithOptions.
+const DefaultTimeout = 60 * time.Second
+
+// Option configures a client created with NewWithOptions.
+type Option func(*Config)
+
+// NewWithOptions creates a client authenticating with the API key.
+// The client talks to DefaultBaseURL using an http.Client with sane timeouts,
+// and bounds every call by DefaultTimeout, unless configured otherwise by the
+// options, which are applied in order.
+func NewWithOptions(apiKey string, opts ...Option) (*Client, error) {
+	cfg := Config{
+		BaseURL: DefaultBaseURL,
+		APIKey:  apiKey,
+		Timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.Client == nil {
+		cfg.Client = newDefaultHTTPClient()
+	}
+
+	return New(cfg)
+}
+
+// newDefaultHTTPClient returns an http.Client that does not hang forever
+// connecting to an unresponsive server. It sets no overall timeout, so the
+// duration of a call is bounded by Config.Timeout or WithRequestTimeout.
+func newDefaultHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   10 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   10,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// WithEnv sets the API key and base URL from the LAGO_API_KEY and
+// LAGO_API_URL environment variables, when they are set.
+func WithEnv() Option {
+	return func(c *Config) {
+		if key := os.Getenv(EnvAPIKey); key != "" {
+			c.APIKey = key
+		}
+		if u := os.Getenv(EnvAPIURL); u != "" {
+			c.BaseURL = u
+		}
+	}
+}
+
+// WithBaseURL sets the base URL of the API, such as a self-hosted instance.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Config) {
+		c.BaseURL = baseURL
+	}
+}
+
+// WithIngestURL sets the base URL events are sent to.
+func WithIngestURL(ingestURL string) Option {
+	return func(c *Config) {
+		c.IngestURL = ingestURL
+	}
+}
+
+// WithUserAgentSuffix appends the suffix to the User-Agent header, to
+// identify the application using the client.
+func WithUserAgentSuffix(suffix string) Option {
+	return func(c *Config) {
+		c.UserAgentSuffix = suffix
+	}
+}
+
+// WithHTTPClient sets the client sending the requests.
+func WithHTTPClient(client HTTPClient) Option {
+	return func(c *Config) {
+		c.Client = client
+	}
+}
+
+// WithLogger enables debug logging of every request to the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Config) {
+		c.Debug = true
+		c.Logger = logger
+	}
+}
+
+// WithTimeout bounds every call, including its retries. Zero disables the
+// timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.Timeout = d
+	}
+}
+
+// WithRetryPolicy sets the policy retrying failed requests.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Config) {
+		c.Retry = policy
+	}
+}
+
+// WithRateLimitPolicy enables client-side rate limiting of the requests.
+func WithRateLimitPolicy(policy *RateLimitPolicy) Option {
+	return func(c *Config) {
+		c.RateLimit = policy
+	}
+}
+
+// WithCircuitBreaker enables failing the requests fast while the API host is
+// failing.
+func WithCircuitBreaker(policy *CircuitBreakerPolicy) Option {
+	return func(c *Config) {
+		c.CircuitBreaker = policy
+	}
+}
+
+// WithInterceptors appends interceptors wrapping every API call.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *Config) {
+		c.Interceptors = append(c.Interceptors, interceptors...)
+	}
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key, value string) Option {
+	return func(c *Config) {
+		if c.Headers == nil {
+			c.Headers = make(http.Header)
+		}
+		c.Headers.Add(key, value)
+	}
+}
@@ -0,0 +1,104 @@
+package lago
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	tests := map[string]struct {
+		env       map[string]string
+		apiKey    string
+		opts      []Option
+		wantURL   string
+		wantAuth  string
+		wantAgent string
+	}{
+		"defaults": {
+			apiKey:    "key",
+			wantURL:   "https://api.getlago.com/api/v1/customers",
+			wantAuth:  "Bearer key",
+			wantAgent: "lago-go github.com/nikola-jokic/lago-go",
+		},
+		"options": {
+			apiKey:    "key",
+			opts:      []Option{WithBaseURL("https://lago.example.com"), WithUserAgentSuffix("billing/1.2")},
+			wantURL:   "https://lago.example.com/api/v1/customers",
+			wantAuth:  "Bearer key",
+			wantAgent: "lago-go github.com/nikola-jokic/lago-go billing/1.2",
+		},
+		"env": {
+			env:       map[string]string{EnvAPIKey: "env-key", EnvAPIURL: "http://localhost:3000"},
+			opts:      []Option{WithEnv()},
+			wantURL:   "http://localhost:3000/api/v1/customers",
+			wantAuth:  "Bearer env-key",
+			wantAgent: "lago-go github.com/nikola-jokic/lago-go",
+		},
+		"options after env": {
+			env:       map[string]string{EnvAPIURL: "http://localhost:3000"},
+			apiKey:    "key",
+			opts:      []Option{WithEnv(), WithBaseURL("https://lago.example.com")},
+			wantURL:   "https://lago.example.com/api/v1/customers",
+			wantAuth:  "Bearer key",
+			wantAgent: "lago-go github.com/nikola-jokic/lago-go",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			var got *http.Request
+			client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				got = req
+				return newTestResponse(http.StatusOK, `{"customers":[]}`), nil
+			})
+
+			opts := append(slices.Clone(tt.opts), WithHTTPClient(client), WithHeader("X-Tenant", "acme"))
+			c, err := NewWithOptions(tt.apiKey, opts...)
+			if err != nil {
+				t.Fatalf("NewWithOptions() = %v", err)
+			}
+
+			if _, err := c.ListCustomers(context.Background(), &CustomerListInput{}); err != nil {
+				t.Fatalf("ListCustomers() = %v", err)
+			}
+
+			if got.URL.String() != tt.wantURL {
+				t.Errorf("URL = %q, want %q", got.URL, tt.wantURL)
+			}
+			if auth := got.Header.Get("Authorization"); auth != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", auth, tt.wantAuth)
+			}
+			if agent := got.Header.Get("User-Agent"); agent != tt.wantAgent {
+				t.Errorf("User-Agent = %q, want %q", agent, tt.wantAgent)
+			}
+			if tenant := got.Header.Get("X-Tenant"); tenant != "acme" {
+				t.Errorf("X-Tenant = %q, want %q", tenant, "acme")
+			}
+		})
+	}
+}
+
+func TestNewWithOptions_Defaults(t *testing.T) {
+	c, err := NewWithOptions("key")
+	if err != nil {
+		t.Fatalf("NewWithOptions() = %v", err)
+	}
+
+	if _, ok := c.client.(*http.Client); !ok {
+		t.Errorf("client = %#v, want an http.Client", c.client)
+	}
+	if c.timeout != DefaultTimeout {
+		t.Errorf("timeout = %s, want %s", c.timeout, DefaultTimeout)
+	}
+
+	t.Setenv(EnvAPIKey, "")
+	if _, err := NewWithOptions("", WithEnv()); err == nil {
+		t.Error("NewWithOptions() without an API key succeeded")
+	}
+}
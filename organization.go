@@ -2,7 +2,10 @@ package lago
 
 import (
 	"context"
+	"slices"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type OrganizationDocumentNumbering string
@@ -12,6 +15,13 @@ const (
 	DocumentNumberingPerOrganization OrganizationDocumentNumbering = "per_organization"
 )
 
+// Email settings enabling the emails sent to customers.
+const (
+	EmailSettingInvoiceFinalized      = "invoice.finalized"
+	EmailSettingCreditNoteCreated     = "credit_note.created"
+	EmailSettingPaymentReceiptCreated = "payment_receipt.created"
+)
+
 type organizationParams struct {
 	Organization *OrganizationInput `json:"organization"`
 }
@@ -58,7 +68,8 @@ type OrganizationResult struct {
 }
 
 type Organization struct {
-	Name string `json:"name,omitempty"`
+	LagoID uuid.UUID `json:"lago_id,omitempty"`
+	Name   string    `json:"name,omitempty"`
 
 	Email                     string                        `json:"email,omitempty"`
 	AddressLine1              string                        `json:"address_line1,omitempty"`
@@ -82,13 +93,44 @@ type Organization struct {
 
 	BillingConfiguration OrganizationBillingConfiguration `json:"billing_configuration,omitempty"`
 
+	// Taxes are the taxes applied by default to customers without taxes of
+	// their own.
 	Taxes []*Tax `json:"taxes,omitempty"`
 
 	CreatedAt time.Time `json:"created_at,omitempty"`
 }
 
+// EmailEnabled reports whether the emails of the setting, such as
+// EmailSettingInvoiceFinalized, are sent to customers.
+func (o *Organization) EmailEnabled(setting string) bool {
+	return slices.Contains(o.EmailSettings, setting)
+}
+
+func (c *Client) GetOrganization(ctx context.Context) (*Organization, error) {
+	u := c.url("GetOrganization", "organizations", nil)
+	result, err := get[OrganizationResult](ctx, c, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Organization, nil
+}
+
+// ListOrganizationInvoiceCustomSections returns the invoice custom sections
+// applied to the invoices of the organization by default.
+func (c *Client) ListOrganizationInvoiceCustomSections(ctx context.Context) ([]*InvoiceCustomSection, error) {
+	sections, err := CollectAll[InvoiceCustomSection](ctx, c.ListInvoiceCustomSections, &InvoiceCustomSectionListInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(sections, func(s *InvoiceCustomSection) bool {
+		return !s.AppliedToOrganization
+	}), nil
+}
+
 func (c *Client) UpdateOrganization(ctx context.Context, organizationInput *OrganizationInput) (*Organization, error) {
-	u := c.url("organizations", nil)
+	u := c.url("UpdateOrganization", "organizations", nil)
 	result, err := put[organizationParams, OrganizationResult](
 		ctx,
 		c,
@@ -0,0 +1,78 @@
+package lago
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestClient_GetOrganization(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet || req.URL.Path != "/api/v1/organizations" {
+			t.Errorf("request = %s %s, want GET /api/v1/organizations", req.Method, req.URL.Path)
+		}
+		return newTestResponse(http.StatusOK, `{"organization":{
+			"name":"Acme",
+			"timezone":"Europe/Paris",
+			"document_numbering":"per_organization",
+			"email_settings":["invoice.finalized"],
+			"billing_configuration":{"invoice_grace_period":3,"document_locale":"fr"},
+			"taxes":[{"code":"vat_fr","rate":20,"applied_to_organization":true}]
+		}}`), nil
+	})
+	c := newTestClient(t, client, nil)
+
+	org, err := c.GetOrganization(context.Background())
+	if err != nil {
+		t.Fatalf("GetOrganization() = %v", err)
+	}
+
+	if org.Timezone != "Europe/Paris" || org.DocumentNumbering != DocumentNumberingPerOrganization {
+		t.Errorf("GetOrganization() = %+v", org)
+	}
+	if org.BillingConfiguration.InvoiceGracePeriod != 3 || org.BillingConfiguration.DocumentLocale != "fr" {
+		t.Errorf("BillingConfiguration = %+v", org.BillingConfiguration)
+	}
+	if len(org.Taxes) != 1 || org.Taxes[0].Code != "vat_fr" {
+		t.Errorf("Taxes = %+v", org.Taxes)
+	}
+
+	tests := map[string]bool{
+		EmailSettingInvoiceFinalized:      true,
+		EmailSettingCreditNoteCreated:     false,
+		EmailSettingPaymentReceiptCreated: false,
+	}
+	for setting, want := range tests {
+		if got := org.EmailEnabled(setting); got != want {
+			t.Errorf("EmailEnabled(%q) = %v, want %v", setting, got, want)
+		}
+	}
+}
+
+func TestClient_ListOrganizationInvoiceCustomSections(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("page") == "2" {
+			return newTestResponse(http.StatusOK, `{"invoice_custom_sections":[
+				{"code":"legal","applied_to_organization":true}
+			],"meta":{"current_page":2,"total_pages":2}}`), nil
+		}
+		return newTestResponse(http.StatusOK, `{"invoice_custom_sections":[
+			{"code":"bank_details","applied_to_organization":true},
+			{"code":"eu_only"}
+		],"meta":{"current_page":1,"next_page":2,"total_pages":2}}`), nil
+	})
+	c := newTestClient(t, client, nil)
+
+	sections, err := c.ListOrganizationInvoiceCustomSections(context.Background())
+	if err != nil {
+		t.Fatalf("ListOrganizationInvoiceCustomSections() = %v", err)
+	}
+
+	var codes []string
+	for _, s := range sections {
+		codes = append(codes, s.Code)
+	}
+	if len(codes) != 2 || codes[0] != "bank_details" || codes[1] != "legal" {
+		t.Errorf("sections = %v, want [bank_details legal]", codes)
+	}
+}
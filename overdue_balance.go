@@ -41,6 +41,6 @@ type OverdueBalance struct {
 }
 
 func (c *Client) ListOverdueBalances(ctx context.Context, OverdueBalanceListInput *OverdueBalanceListInput) (*OverdueBalanceList, error) {
-	u := c.url("analytics/overdue_balance", OverdueBalanceListInput.query())
+	u := c.url("ListOverdueBalances", "analytics/overdue_balance", OverdueBalanceListInput.query())
 	return get[OverdueBalanceList](ctx, c, u)
 }
@@ -1,5 +1,13 @@
 package lago
 
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+)
+
 type Metadata struct {
 	CurrentPage int `json:"current_page,omitempty"`
 	NextPage    int `json:"next_page,omitempty"`
@@ -7,3 +15,224 @@ type Metadata struct {
 	TotalPages  int `json:"total_pages,omitempty"`
 	TotalCount  int `json:"total_count,omitempty"`
 }
+
+// ListInput is implemented by the inputs of the paginated List* methods,
+// such as *InvoiceListInput and *CustomerListInput.
+type ListInput interface {
+	query() url.Values
+	setPage(page int)
+}
+
+// Page is implemented by the results of the paginated List* methods,
+// such as *InvoiceList and *CustomerList.
+type Page[T any] interface {
+	items() []*T
+	meta() Metadata
+}
+
+type iterateOptions struct {
+	prefetch bool
+}
+
+// IterateOption configures Iterate and CollectAll.
+type IterateOption func(*iterateOptions)
+
+// WithPrefetch fetches the next page concurrently while the items of the
+// current page are being consumed.
+func WithPrefetch() IterateOption {
+	return func(o *iterateOptions) {
+		o.prefetch = true
+	}
+}
+
+// Iterate returns an iterator over the items of every page returned by list,
+// starting at the page set on the input and following Metadata.NextPage
+// until it is zero. The input is not modified.
+//
+// Iteration stops after the first error, which is yielded with a nil item.
+// A NextPage that does not move past the current page is an error, so a
+// misbehaving server cannot make the iteration loop forever.
+//
+//	for invoice, err := range lago.Iterate[lago.Invoice](ctx, client.ListInvoice, &lago.InvoiceListInput{PerPage: 100}) {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+func Iterate[T any, I interface {
+	*X
+	ListInput
+}, P Page[T], X any](ctx context.Context, list func(context.Context, I) (P, error), input I, opts ...IterateOption) iter.Seq2[*T, error] {
+	var o iterateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var base X
+	if input != nil {
+		base = *input
+	}
+
+	fetch := func(ctx context.Context, page int) (P, error) {
+		in := I(new(X))
+		*in = base
+		if page > 0 {
+			in.setPage(page)
+		}
+		return list(ctx, in)
+	}
+
+	type result struct {
+		page P
+		err  error
+	}
+
+	return func(yield func(*T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		requested := 0
+		page, err := fetch(ctx, requested)
+		for {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			meta := page.meta()
+			current, next := cmp.Or(meta.CurrentPage, requested), meta.NextPage
+			if next != 0 && next <= current {
+				err = fmt.Errorf("lago: next page %d does not follow page %d", next, current)
+				next = 0
+			}
+
+			var prefetched chan result
+			if next != 0 && o.prefetch {
+				prefetched = make(chan result, 1)
+				go func() {
+					page, err := fetch(ctx, next)
+					prefetched <- result{page: page, err: err}
+				}()
+			}
+
+			for _, item := range page.items() {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if next == 0 {
+				return
+			}
+
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			requested = next
+			if prefetched != nil {
+				r := <-prefetched
+				page, err = r.page, r.err
+			} else {
+				page, err = fetch(ctx, next)
+			}
+		}
+	}
+}
+
+// CollectAll returns the items of every page returned by list.
+// See Iterate for details.
+func CollectAll[T any, I interface {
+	*X
+	ListInput
+}, P Page[T], X any](ctx context.Context, list func(context.Context, I) (P, error), input I, opts ...IterateOption) ([]*T, error) {
+	var all []*T
+	for item, err := range Iterate[T](ctx, list, input, opts...) {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, item)
+	}
+	return all, nil
+}
+
+func (i *AddOnListInput) setPage(page int) { i.Page = page }
+func (l *AddOnList) items() []*AddOn       { return l.AddOns }
+func (l *AddOnList) meta() Metadata        { return l.Meta }
+
+func (i *BillableMetricListInput) setPage(page int)    { i.Page = page }
+func (l *BillableMetricList) items() []*BillableMetric { return l.BillableMetrics }
+func (l *BillableMetricList) meta() Metadata           { return l.Meta }
+
+func (i *CouponListInput) setPage(page int) { i.Page = page }
+func (l *CouponList) items() []*Coupon      { return l.Coupons }
+func (l *CouponList) meta() Metadata        { return l.Meta }
+
+func (i *AppliedCouponListInput) setPage(page int)   { i.Page = page }
+func (l *AppliedCouponList) items() []*AppliedCoupon { return l.AppliedCoupons }
+func (l *AppliedCouponList) meta() Metadata          { return l.Meta }
+
+func (i *CreditListInput) setPage(page int)    { i.Page = page }
+func (l *CreditNoteList) items() []*CreditNote { return l.CreditNotes }
+func (l *CreditNoteList) meta() Metadata       { return l.Meta }
+
+func (i *CustomerListInput) setPage(page int) { i.Page = page }
+func (l *CustomerList) items() []*Customer    { return l.Customers }
+func (l *CustomerList) meta() Metadata        { return l.Meta }
+
+func (i *FeeListInput) setPage(page int) { i.Page = page }
+func (l *FeeList) items() []*Fee         { return l.Fees }
+func (l *FeeList) meta() Metadata        { return l.Meta }
+
+func (i *IntegrationListInput) setPage(page int) { i.Page = page }
+func (l *IntegrationList) items() []*Integration { return l.Integrations }
+func (l *IntegrationList) meta() Metadata        { return l.Meta }
+
+func (i *InvoiceCustomSectionListInput) setPage(page int)          { i.Page = page }
+func (l *InvoiceCustomSectionList) items() []*InvoiceCustomSection { return l.InvoiceCustomSections }
+func (l *InvoiceCustomSectionList) meta() Metadata                 { return l.Meta }
+
+func (i *InvoiceListInput) setPage(page int) { i.Page = page }
+func (l *InvoiceList) items() []*Invoice     { return l.Invoices }
+func (l *InvoiceList) meta() Metadata        { return l.Meta }
+
+func (i *PaymentListInput) setPage(page int) { i.Page = page }
+func (l *PaymentList) items() []*Payment     { return l.Payments }
+func (l *PaymentList) meta() Metadata        { return l.Meta }
+
+func (i *PaymentReceiptListInput) setPage(page int)    { i.Page = page }
+func (l *PaymentReceiptList) items() []*PaymentReceipt { return l.PaymentReceipts }
+func (l *PaymentReceiptList) meta() Metadata           { return l.Meta }
+
+func (i *PaymentRequestListInput) setPage(page int)    { i.Page = page }
+func (l *PaymentRequestList) items() []*PaymentRequest { return l.PaymentRequests }
+func (l *PaymentRequestList) meta() Metadata           { return l.Meta }
+
+func (i *PlanListInput) setPage(page int) { i.Page = page }
+func (l *PlanList) items() []*Plan        { return l.Plans }
+func (l *PlanList) meta() Metadata        { return l.Meta }
+
+func (i *SubscriptionListInput) setPage(page int)  { i.Page = page }
+func (l *SubscriptionList) items() []*Subscription { return l.Subscriptions }
+func (l *SubscriptionList) meta() Metadata         { return l.Meta }
+
+func (i *TaxListInput) setPage(page int) { i.Page = page }
+func (l *TaxList) items() []*Tax         { return l.Taxes }
+func (l *TaxList) meta() Metadata        { return l.Meta }
+
+func (i *WalletListInput) setPage(page int) { i.Page = page }
+func (l *WalletList) items() []*Wallet      { return l.Wallets }
+func (l *WalletList) meta() Metadata        { return l.Meta }
+
+func (i *WalletTransactionListInput) setPage(page int)       { i.Page = page }
+func (l *WalletTransactionList) items() []*WalletTransaction { return l.WalletTransactions }
+func (l *WalletTransactionList) meta() Metadata              { return l.Meta }
+
+func (i *WebhookEndpointListInput) setPage(page int)     { i.Page = page }
+func (l *WebhookEndpointList) items() []*WebhookEndpoint { return l.WebhookEndpoints }
+func (l *WebhookEndpointList) meta() Metadata            { return l.Meta }
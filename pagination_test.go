@@ -0,0 +1,211 @@
+package lago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func newPagedCustomersClient(t *testing.T, pages int) (*Client, func() []int) {
+	t.Helper()
+
+	var (
+		mu        sync.Mutex
+		requested []int
+	)
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		mu.Lock()
+		requested = append(requested, page)
+		mu.Unlock()
+
+		next := page + 1
+		if page == pages {
+			next = 0
+		}
+
+		body := fmt.Sprintf(
+			`{"customers":[{"external_id":"%d-a"},{"external_id":"%d-b"}],"meta":{"current_page":%d,"next_page":%d,"total_pages":%d}}`,
+			page, page, page, next, pages,
+		)
+		return newTestResponse(http.StatusOK, body), nil
+	})
+
+	return newTestClient(t, client, nil), func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(requested)
+	}
+}
+
+func TestCollectAll(t *testing.T) {
+	for name, opts := range map[string][]IterateOption{
+		"sequential": nil,
+		"prefetch":   {WithPrefetch()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, requested := newPagedCustomersClient(t, 3)
+
+			input := &CustomerListInput{PerPage: 2}
+			customers, err := CollectAll[Customer](context.Background(), c.ListCustomers, input, opts...)
+			if err != nil {
+				t.Fatalf("CollectAll() = %v", err)
+			}
+
+			var got []string
+			for _, customer := range customers {
+				got = append(got, customer.ExternalID)
+			}
+			want := []string{"1-a", "1-b", "2-a", "2-b", "3-a", "3-b"}
+			if !slices.Equal(got, want) {
+				t.Errorf("CollectAll() = %v, want %v", got, want)
+			}
+
+			if got, want := requested(), []int{1, 2, 3}; !slices.Equal(got, want) {
+				t.Errorf("requested pages = %v, want %v", got, want)
+			}
+			if input.Page != 0 {
+				t.Errorf("input.Page = %d, want it unchanged", input.Page)
+			}
+		})
+	}
+}
+
+func TestIterate_StartPage(t *testing.T) {
+	c, requested := newPagedCustomersClient(t, 3)
+
+	customers, err := CollectAll[Customer](context.Background(), c.ListCustomers, &CustomerListInput{Page: 2})
+	if err != nil {
+		t.Fatalf("CollectAll() = %v", err)
+	}
+	if len(customers) != 4 {
+		t.Errorf("len(customers) = %d, want 4", len(customers))
+	}
+	if got, want := requested(), []int{2, 3}; !slices.Equal(got, want) {
+		t.Errorf("requested pages = %v, want %v", got, want)
+	}
+}
+
+func TestIterate_Break(t *testing.T) {
+	c, requested := newPagedCustomersClient(t, 3)
+
+	n := 0
+	for _, err := range Iterate[Customer](context.Background(), c.ListCustomers, nil) {
+		if err != nil {
+			t.Fatalf("Iterate() = %v", err)
+		}
+		n++
+		if n == 3 {
+			break
+		}
+	}
+
+	if got, want := requested(), []int{1, 2}; !slices.Equal(got, want) {
+		t.Errorf("requested pages = %v, want %v", got, want)
+	}
+}
+
+func TestIterate_Error(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		return newTestResponse(http.StatusUnauthorized, `{"status":401,"error":"Unauthorized"}`), nil
+	})
+	c := newTestClient(t, client, nil)
+
+	if _, err := CollectAll[Invoice](context.Background(), c.ListInvoice, &InvoiceListInput{}); err == nil {
+		t.Fatal("CollectAll() error = nil, want error")
+	}
+}
+
+func TestIterate_ContextCancelled(t *testing.T) {
+	c, _ := newPagedCustomersClient(t, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var gotErr error
+	for _, err := range Iterate[Customer](ctx, c.ListCustomers, &CustomerListInput{}) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		cancel()
+	}
+
+	if gotErr != context.Canceled {
+		t.Errorf("Iterate() error = %v, want %v", gotErr, context.Canceled)
+	}
+}
+
+func TestIterate_NextPageNotAdvancing(t *testing.T) {
+	tests := map[string]struct {
+		meta          func(page int) string
+		opts          []IterateOption
+		wantRequested []int
+	}{
+		"same page": {
+			meta:          func(page int) string { return fmt.Sprintf(`{"current_page":%d,"next_page":2}`, page) },
+			wantRequested: []int{1, 2},
+		},
+		"same page with prefetch": {
+			meta:          func(page int) string { return fmt.Sprintf(`{"current_page":%d,"next_page":2}`, page) },
+			opts:          []IterateOption{WithPrefetch()},
+			wantRequested: []int{1, 2},
+		},
+		"previous page": {
+			meta:          func(page int) string { return fmt.Sprintf(`{"current_page":%d,"next_page":1}`, page) },
+			wantRequested: []int{1},
+		},
+		"without current page": {
+			meta:          func(page int) string { return `{"next_page":2}` },
+			wantRequested: []int{1, 2},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requested []int
+			client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+				page = max(page, 1)
+				requested = append(requested, page)
+				if len(requested) > 5 {
+					return nil, errors.New("iteration does not stop")
+				}
+				body := fmt.Sprintf(`{"customers":[{"external_id":"%d"}],"meta":%s}`, page, tt.meta(page))
+				return newTestResponse(http.StatusOK, body), nil
+			})
+			c := newTestClient(t, client, nil)
+
+			var (
+				items  int
+				gotErr error
+			)
+			for _, err := range Iterate[Customer](context.Background(), c.ListCustomers, &CustomerListInput{}, tt.opts...) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				items++
+			}
+
+			if gotErr == nil {
+				t.Fatal("Iterate() error = nil, want error")
+			}
+			if items != len(tt.wantRequested) {
+				t.Errorf("got %d items, want %d", items, len(tt.wantRequested))
+			}
+			if !slices.Equal(requested, tt.wantRequested) {
+				t.Errorf("requested pages = %v, want %v", requested, tt.wantRequested)
+			}
+		})
+	}
+}
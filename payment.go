@@ -0,0 +1,119 @@
+package lago
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentType string
+
+const (
+	PaymentTypeManual   PaymentType = "manual"
+	PaymentTypeProvider PaymentType = "provider"
+)
+
+type paymentParams struct {
+	Payment *PaymentInput `json:"payment"`
+}
+
+type paymentResult struct {
+	Payment *Payment `json:"payment,omitempty"`
+}
+
+type PaymentList struct {
+	Payments []*Payment `json:"payments,omitempty"`
+	Meta     Metadata   `json:"meta,omitempty"`
+}
+
+// PaymentInput records a payment received outside of Lago, such as a bank
+// transfer, against an invoice.
+type PaymentInput struct {
+	LagoInvoiceID string     `json:"invoice_id,omitempty"`
+	AmountCents   int        `json:"amount_cents,omitempty"`
+	Reference     string     `json:"reference,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+}
+
+type PaymentListInput struct {
+	PerPage int `json:"per_page,omitempty,string"`
+	Page    int `json:"page,omitempty,string"`
+
+	ExternalCustomerID string `json:"external_customer_id,omitempty"`
+	LagoInvoiceID      string `json:"invoice_id,omitempty"`
+}
+
+func (i *PaymentListInput) query() url.Values {
+	q := make(url.Values)
+
+	if i.PerPage > 0 {
+		q.Add("per_page", strconv.Itoa(i.PerPage))
+	}
+
+	if i.Page > 0 {
+		q.Add("page", strconv.Itoa(i.Page))
+	}
+
+	if i.ExternalCustomerID != "" {
+		q.Add("external_customer_id", i.ExternalCustomerID)
+	}
+
+	if i.LagoInvoiceID != "" {
+		q.Add("invoice_id", i.LagoInvoiceID)
+	}
+
+	return q
+}
+
+type Payment struct {
+	LagoID              uuid.UUID   `json:"lago_id,omitempty"`
+	LagoCustomerID      uuid.UUID   `json:"lago_customer_id,omitempty"`
+	ExternalCustomerID  string      `json:"external_customer_id,omitempty"`
+	LagoInvoiceIDs      []uuid.UUID `json:"invoice_ids,omitempty"`
+	LagoPayableID       uuid.UUID   `json:"lago_payable_id,omitempty"`
+	PayableType         string      `json:"payable_type,omitempty"`
+	AmountCents         int         `json:"amount_cents,omitempty"`
+	AmountCurrency      Currency    `json:"amount_currency,omitempty"`
+	Status              string      `json:"status,omitempty"`
+	PaymentStatus       string      `json:"payment_status,omitempty"`
+	Type                PaymentType `json:"type,omitempty"`
+	Reference           string      `json:"reference,omitempty"`
+	PaymentProviderCode string      `json:"payment_provider_code,omitempty"`
+	PaymentProviderType string      `json:"payment_provider_type,omitempty"`
+	ExternalPaymentID   string      `json:"external_payment_id,omitempty"`
+	CreatedAt           time.Time   `json:"created_at,omitempty"`
+}
+
+func (c *Client) GetPayment(ctx context.Context, paymentID string) (*Payment, error) {
+	u := c.url("GetPayment", "payments/{lago_id}", nil, paymentID)
+	result, err := get[paymentResult](ctx, c, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Payment, nil
+}
+
+func (c *Client) ListPayments(ctx context.Context, paymentListInput *PaymentListInput) (*PaymentList, error) {
+	u := c.url("ListPayments", "payments", paymentListInput.query())
+	return get[PaymentList](ctx, c, u)
+}
+
+// CreatePayment records a manual payment against an invoice.
+func (c *Client) CreatePayment(ctx context.Context, paymentInput *PaymentInput) (*Payment, error) {
+	u := c.url("CreatePayment", "payments", nil)
+	result, err := post[paymentParams, paymentResult](
+		ctx,
+		c,
+		u,
+		&paymentParams{Payment: paymentInput},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Payment, nil
+}
@@ -0,0 +1,74 @@
+package lago
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type paymentReceiptResult struct {
+	PaymentReceipt *PaymentReceipt `json:"payment_receipt,omitempty"`
+}
+
+type PaymentReceiptList struct {
+	PaymentReceipts []*PaymentReceipt `json:"payment_receipts,omitempty"`
+	Meta            Metadata          `json:"meta,omitempty"`
+}
+
+type PaymentReceiptListInput struct {
+	PerPage int `json:"per_page,omitempty,string"`
+	Page    int `json:"page,omitempty,string"`
+
+	ExternalCustomerID string `json:"external_customer_id,omitempty"`
+	LagoInvoiceID      string `json:"invoice_id,omitempty"`
+}
+
+func (i *PaymentReceiptListInput) query() url.Values {
+	q := make(url.Values)
+
+	if i.PerPage > 0 {
+		q.Add("per_page", strconv.Itoa(i.PerPage))
+	}
+
+	if i.Page > 0 {
+		q.Add("page", strconv.Itoa(i.Page))
+	}
+
+	if i.ExternalCustomerID != "" {
+		q.Add("external_customer_id", i.ExternalCustomerID)
+	}
+
+	if i.LagoInvoiceID != "" {
+		q.Add("invoice_id", i.LagoInvoiceID)
+	}
+
+	return q
+}
+
+// PaymentReceipt is the receipt Lago generates for a payment.
+type PaymentReceipt struct {
+	LagoID    uuid.UUID `json:"lago_id,omitempty"`
+	Number    string    `json:"number,omitempty"`
+	FileURL   string    `json:"file_url,omitempty"`
+	XMLURL    string    `json:"xml_url,omitempty"`
+	Payment   *Payment  `json:"payment,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+func (c *Client) GetPaymentReceipt(ctx context.Context, paymentReceiptID string) (*PaymentReceipt, error) {
+	u := c.url("GetPaymentReceipt", "payment_receipts/{lago_id}", nil, paymentReceiptID)
+	result, err := get[paymentReceiptResult](ctx, c, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.PaymentReceipt, nil
+}
+
+func (c *Client) ListPaymentReceipts(ctx context.Context, paymentReceiptListInput *PaymentReceiptListInput) (*PaymentReceiptList, error) {
+	u := c.url("ListPaymentReceipts", "payment_receipts", paymentReceiptListInput.query())
+	return get[PaymentReceiptList](ctx, c, u)
+}
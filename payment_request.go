@@ -15,6 +15,7 @@ type paymentRequestResult struct {
 
 type PaymentRequestList struct {
 	PaymentRequests []*PaymentRequest `json:"payment_requests,omitempty"`
+	Meta            Metadata          `json:"meta,omitempty"`
 }
 
 type PaymentRequestListInput struct {
@@ -65,12 +66,12 @@ type PaymentRequestInput struct {
 }
 
 func (c *Client) ListPaymentRequests(ctx context.Context, paymentRequestListInput *PaymentRequestListInput) (*PaymentRequestList, error) {
-	u := c.url("payment_requests", paymentRequestListInput.query())
+	u := c.url("ListPaymentRequests", "payment_requests", paymentRequestListInput.query())
 	return get[PaymentRequestList](ctx, c, u)
 }
 
 func (c *Client) CreatePaymentRequest(ctx context.Context, paymentRequestInput *PaymentRequestInput) (*PaymentRequest, error) {
-	u := c.url("payment_requests", nil)
+	u := c.url("CreatePaymentRequest", "payment_requests", nil)
 	result, err := post[paymentRequestParams, paymentRequestResult](
 		ctx,
 		c,
@@ -0,0 +1,92 @@
+package lago
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_Payments(t *testing.T) {
+	ctx := context.Background()
+	paidAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := map[string]requestTest{
+		"create": {
+			call: func(c *Client) error {
+				payment, err := c.CreatePayment(ctx, &PaymentInput{
+					LagoInvoiceID: "486b147a-02a1-4ccf-8603-f3541fc25e7a",
+					AmountCents:   12000,
+					Reference:     "wire 42",
+					PaidAt:        &paidAt,
+				})
+				if err == nil && payment.Type != PaymentTypeManual {
+					t.Errorf("Type = %q, want %q", payment.Type, PaymentTypeManual)
+				}
+				return err
+			},
+			response:   `{"payment":{"amount_cents":12000,"type":"manual","reference":"wire 42"}}`,
+			wantMethod: http.MethodPost,
+			wantURI:    "/api/v1/payments",
+			wantBody:   `{"payment":{"invoice_id":"486b147a-02a1-4ccf-8603-f3541fc25e7a","amount_cents":12000,"reference":"wire 42","paid_at":"2026-03-02T10:00:00Z"}}`,
+		},
+		"list": {
+			call: func(c *Client) error {
+				_, err := c.ListPayments(ctx, &PaymentListInput{ExternalCustomerID: "cus", LagoInvoiceID: "inv"})
+				return err
+			},
+			response:   `{"payments":[],"meta":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/payments?external_customer_id=cus&invoice_id=inv",
+		},
+		"get": {
+			call: func(c *Client) error {
+				_, err := c.GetPayment(ctx, "pay")
+				return err
+			},
+			response:   `{"payment":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/payments/pay",
+		},
+		"list receipts": {
+			call: func(c *Client) error {
+				_, err := c.ListPaymentReceipts(ctx, &PaymentReceiptListInput{ExternalCustomerID: "cus", LagoInvoiceID: "inv"})
+				return err
+			},
+			response:   `{"payment_receipts":[],"meta":{}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/payment_receipts?external_customer_id=cus&invoice_id=inv",
+		},
+		"get receipt": {
+			call: func(c *Client) error {
+				receipt, err := c.GetPaymentReceipt(ctx, "rec")
+				if err == nil && (receipt.Number != "RCPT-1" || receipt.Payment.AmountCents != 12000) {
+					t.Errorf("GetPaymentReceipt() = %+v", receipt)
+				}
+				return err
+			},
+			response:   `{"payment_receipt":{"number":"RCPT-1","payment":{"amount_cents":12000}}}`,
+			wantMethod: http.MethodGet,
+			wantURI:    "/api/v1/payment_receipts/rec",
+		},
+	}
+
+	runRequestTests(t, tests)
+}
+
+func TestWebhookEvent_PaymentReceipt(t *testing.T) {
+	var event WebhookEvent
+	body := `{"webhook_type":"payment_receipt.created","object_type":"payment_receipt","payment_receipt":{"number":"RCPT-1"}}`
+	if err := json.Unmarshal([]byte(body), &event); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+
+	receipt, err := event.PaymentReceipt()
+	if err != nil {
+		t.Fatalf("PaymentReceipt() = %v", err)
+	}
+	if event.WebhookType != WebhookPaymentReceiptCreated || receipt.Number != "RCPT-1" {
+		t.Errorf("event = %s, receipt = %+v", event.WebhookType, receipt)
+	}
+}
@@ -121,7 +121,7 @@ type Plan struct {
 }
 
 func (c *Client) GetPlan(ctx context.Context, planCode string) (*Plan, error) {
-	u := c.url("plans/"+planCode, nil)
+	u := c.url("GetPlan", "plans/{code}", nil, planCode)
 	result, err := get[planResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -131,12 +131,12 @@ func (c *Client) GetPlan(ctx context.Context, planCode string) (*Plan, error) {
 }
 
 func (c *Client) ListPlans(ctx context.Context, planListInput *PlanListInput) (*PlanList, error) {
-	u := c.url("plans", planListInput.query())
+	u := c.url("ListPlans", "plans", planListInput.query())
 	return get[PlanList](ctx, c, u)
 }
 
 func (c *Client) CreatePlan(ctx context.Context, planInput *PlanInput) (*Plan, error) {
-	u := c.url("plans", nil)
+	u := c.url("CreatePlan", "plans", nil)
 	result, err := post[planParams, planResult](
 		ctx,
 		c,
@@ -151,7 +151,7 @@ func (c *Client) CreatePlan(ctx context.Context, planInput *PlanInput) (*Plan, e
 }
 
 func (c *Client) UpdatePlan(ctx context.Context, planInput *PlanInput) (*Plan, error) {
-	u := c.url("plans/"+planInput.Code, nil)
+	u := c.url("UpdatePlan", "plans/{code}", nil, planInput.Code)
 	result, err := put[planParams, planResult](
 		ctx,
 		c,
@@ -166,7 +166,7 @@ func (c *Client) UpdatePlan(ctx context.Context, planInput *PlanInput) (*Plan, e
 }
 
 func (c *Client) DeletePlan(ctx context.Context, planCode string) (*Plan, error) {
-	u := c.url("plans/"+planCode, nil)
+	u := c.url("DeletePlan", "plans/{code}", nil, planCode)
 
 	result, err := delete[planResult](ctx, c, u)
 	if err != nil {
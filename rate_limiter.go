@@ -0,0 +1,275 @@
+package lago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is the budget of a token bucket: Rate requests per second on
+// average, with bursts of up to Burst requests.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l RateLimit) validate() error {
+	if l.Rate <= 0 {
+		return errors.New("Rate must be positive")
+	}
+	if l.Burst < 1 {
+		return errors.New("Burst must be at least 1")
+	}
+	return nil
+}
+
+// RateLimitPolicy configures the client-side rate limiting of requests.
+//
+// Event ingestion requests and management requests have separate budgets.
+// Each budget adapts to the responses: a 429 halves its rate and pauses it
+// for the Retry-After duration, an exhausted X-RateLimit-Remaining pauses it
+// until X-RateLimit-Reset, and successful responses restore the rate
+// gradually.
+type RateLimitPolicy struct {
+	// Management limits every request but event ingestion.
+	Management RateLimit
+	// Ingest limits the requests sending events.
+	Ingest RateLimit
+}
+
+// DefaultRateLimitPolicy returns a conservative policy of 10 management
+// requests and 50 event ingestion requests per second.
+func DefaultRateLimitPolicy() *RateLimitPolicy {
+	return &RateLimitPolicy{
+		Management: RateLimit{Rate: 10, Burst: 20},
+		Ingest:     RateLimit{Rate: 50, Burst: 100},
+	}
+}
+
+func (p *RateLimitPolicy) Validate() error {
+	if err := p.Management.validate(); err != nil {
+		return fmt.Errorf("Management: %v", err)
+	}
+	if err := p.Ingest.validate(); err != nil {
+		return fmt.Errorf("Ingest: %v", err)
+	}
+	return nil
+}
+
+const (
+	// minRateFraction bounds how far a 429 can slow a limiter down.
+	minRateFraction = 0.1
+	// rateRecoverySteps is the number of successful responses restoring the
+	// configured rate of a limiter from zero.
+	rateRecoverySteps = 20
+)
+
+// RateLimiter is an adaptive token bucket.
+// A nil limiter does not limit anything.
+// It is safe for concurrent use by multiple goroutines.
+type RateLimiter struct {
+	maxRate float64
+	burst   float64
+
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	// last is when the tokens were last refilled. It is in the future while
+	// the limiter is paused.
+	last time.Time
+	// pauses counts the pauses. A pause cancels the tokens reserved before
+	// it, so the requests waiting for them reserve again.
+	pauses uint64
+}
+
+// NewRateLimiter returns a limiter with the given budget.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		maxRate: limit.Rate,
+		burst:   float64(limit.Burst),
+		rate:    limit.Rate,
+		tokens:  float64(limit.Burst),
+		last:    time.Now(),
+	}
+}
+
+// Wait blocks until the limiter lets a request through, or the context is
+// done. It does not take a token, so callers can pace their work without
+// slowing down the requests that follow.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		d := l.delay(time.Now())
+		if d <= 0 {
+			return nil
+		}
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// take blocks until a token is available, and takes it. A request waiting
+// for its token when the limiter is paused waits for a new one, after the
+// pause and at the rate it left.
+func (l *RateLimiter) take(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		d, pauses := l.reserve(time.Now())
+		if d <= 0 {
+			return nil
+		}
+
+		if err := sleep(ctx, d); err != nil {
+			l.refund(pauses)
+			return err
+		}
+		if !l.pausedSince(pauses) {
+			return nil
+		}
+	}
+}
+
+// Rate returns the current rate of the limiter, in requests per second.
+func (l *RateLimiter) Rate() float64 {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// reserve takes a token and returns how long to wait before using it, along
+// with the number of pauses it was reserved after.
+func (l *RateLimiter) reserve(now time.Time) (time.Duration, uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(now)
+	l.tokens--
+	return l.until(now, 0), l.pauses
+}
+
+// refund gives back a token reserved after the pauses but not used.
+func (l *RateLimiter) refund(pauses uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// A pause already canceled the token.
+	if pauses == l.pauses {
+		l.tokens = min(l.tokens+1, l.burst)
+	}
+}
+
+// pausedSince reports whether the limiter was paused after the pauses.
+func (l *RateLimiter) pausedSince(pauses uint64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return pauses != l.pauses
+}
+
+// delay returns how long until a token is available.
+func (l *RateLimiter) delay(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(now)
+	return l.until(now, 1)
+}
+
+// until returns how long until the bucket holds n tokens.
+func (l *RateLimiter) until(now time.Time, n float64) time.Duration {
+	deficit := max(n-l.tokens, 0)
+	return l.last.Add(time.Duration(deficit / l.rate * float64(time.Second))).Sub(now)
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	if !now.After(l.last) {
+		return
+	}
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// observe adapts the limiter to the response.
+func (l *RateLimiter) observe(res *http.Response) {
+	if l == nil || res == nil {
+		return
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		l.rate = max(l.rate/2, l.maxRate*minRateFraction)
+		d, ok := retryAfter(res.Header, now)
+		if !ok {
+			d = time.Duration(float64(time.Second) / l.rate)
+		}
+		l.pause(now, d)
+		return
+	}
+
+	l.rate = min(l.rate+l.maxRate/rateRecoverySteps, l.maxRate)
+
+	remaining, err := strconv.Atoi(res.Header.Get(RateLimitRemainingHeader))
+	if err != nil || remaining > 0 {
+		return
+	}
+	if reset, err := strconv.Atoi(res.Header.Get(RateLimitResetHeader)); err == nil && reset > 0 {
+		l.pause(now, time.Duration(reset)*time.Second)
+	}
+}
+
+// pause stops handing out tokens for d. The tokens are dropped, and the
+// tokens reserved are canceled, so the requests held back are spread at the
+// current rate once the pause ends.
+func (l *RateLimiter) pause(now time.Time, d time.Duration) {
+	l.refill(now)
+	if until := now.Add(d); until.After(l.last) {
+		l.last = until
+	}
+	l.tokens = 0
+	l.pauses++
+}
+
+// rateLimiter returns the limiter of the request's budget. Only the requests
+// sending events use the ingest budget, unlike events/estimate_fees.
+func (c *Client) rateLimiter(req *http.Request) *RateLimiter {
+	if req.Method != http.MethodPost {
+		return c.managementLimiter
+	}
+	switch strings.TrimPrefix(req.URL.Path, ApiV1Path) {
+	case "events", "events/batch":
+		return c.ingestLimiter
+	default:
+		return c.managementLimiter
+	}
+}
+
+// ManagementRateLimiter returns the limiter of the management requests, or
+// nil when Config.RateLimit is not set.
+func (c *Client) ManagementRateLimiter() *RateLimiter {
+	return c.managementLimiter
+}
+
+// IngestRateLimiter returns the limiter of the event ingestion requests, or
+// nil when Config.RateLimit is not set.
+func (c *Client) IngestRateLimiter() *RateLimiter {
+	return c.ingestLimiter
+}
@@ -0,0 +1,231 @@
+package lago
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Take(t *testing.T) {
+	l := NewRateLimiter(RateLimit{Rate: 100, Burst: 2})
+
+	start := time.Now()
+	for range 6 {
+		if err := l.take(context.Background()); err != nil {
+			t.Fatalf("take() = %v", err)
+		}
+	}
+
+	// The burst passes immediately, the 4 other requests are paced at 10ms.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("6 requests took %s, want at least 35ms", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitCanceled(t *testing.T) {
+	l := NewRateLimiter(RateLimit{Rate: 1, Burst: 1})
+	if err := l.take(context.Background()); err != nil {
+		t.Fatalf("take() = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	var disabled *RateLimiter
+	if err := disabled.Wait(ctx); err != nil {
+		t.Errorf("nil Wait() = %v", err)
+	}
+}
+
+func TestRateLimiter_PauseWhileWaiting(t *testing.T) {
+	l := NewRateLimiter(RateLimit{Rate: 100, Burst: 1})
+	if err := l.take(context.Background()); err != nil {
+		t.Fatalf("take() = %v", err)
+	}
+
+	// The waiting requests are scheduled every 10ms.
+	const waiting = 5
+	done := make(chan time.Time, waiting)
+	for range waiting {
+		go func() {
+			if err := l.take(context.Background()); err != nil {
+				t.Errorf("take() = %v", err)
+			}
+			done <- time.Now()
+		}()
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	// A 429 halves the rate and pauses the limiter for 20ms.
+	paused := time.Now()
+	l.observe(rateLimitedResponse(http.StatusTooManyRequests))
+
+	var last time.Time
+	for range waiting {
+		at := <-done
+		if at.Before(paused.Add(20 * time.Millisecond)) {
+			t.Errorf("request sent %s after the pause started, want after the pause", at.Sub(paused))
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+	// They are spread at 20ms once the pause ends.
+	if elapsed := last.Sub(paused); elapsed < 100*time.Millisecond {
+		t.Errorf("requests sent within %s of the pause, want at least 100ms", elapsed)
+	}
+}
+
+func TestRateLimiter_RefundBurst(t *testing.T) {
+	l := NewRateLimiter(RateLimit{Rate: 1, Burst: 1})
+
+	now := time.Now()
+	_, pauses := l.reserve(now)
+	l.refill(now.Add(time.Hour))
+	l.refund(pauses)
+
+	if l.tokens != l.burst {
+		t.Errorf("tokens = %v, want the burst of %v", l.tokens, l.burst)
+	}
+}
+
+func TestRateLimiter_Observe(t *testing.T) {
+	tests := map[string]struct {
+		responses []*http.Response
+		wantRate  float64
+		wantDelay time.Duration
+	}{
+		"success": {
+			responses: []*http.Response{newTestResponse(http.StatusOK, "")},
+			wantRate:  100,
+		},
+		"too many requests": {
+			responses: []*http.Response{rateLimitedResponse(http.StatusTooManyRequests, "Retry-After", "2")},
+			wantRate:  50,
+			wantDelay: 2 * time.Second,
+		},
+		"minimum rate": {
+			responses: []*http.Response{
+				rateLimitedResponse(http.StatusTooManyRequests, "Retry-After", "0"),
+				rateLimitedResponse(http.StatusTooManyRequests, "Retry-After", "0"),
+				rateLimitedResponse(http.StatusTooManyRequests, "Retry-After", "0"),
+				rateLimitedResponse(http.StatusTooManyRequests, "Retry-After", "0"),
+				rateLimitedResponse(http.StatusTooManyRequests, "Retry-After", "0"),
+			},
+			wantRate:  10,
+			wantDelay: 100 * time.Millisecond,
+		},
+		"recovery": {
+			responses: []*http.Response{
+				rateLimitedResponse(http.StatusTooManyRequests, "Retry-After", "0"),
+				newTestResponse(http.StatusOK, ""),
+				newTestResponse(http.StatusOK, ""),
+			},
+			wantRate: 60,
+		},
+		"remaining exhausted": {
+			responses: []*http.Response{
+				rateLimitedResponse(http.StatusOK, RateLimitRemainingHeader, "0", RateLimitResetHeader, "3"),
+			},
+			wantRate:  100,
+			wantDelay: 3 * time.Second,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			l := NewRateLimiter(RateLimit{Rate: 100, Burst: 10})
+			for _, res := range tt.responses {
+				l.observe(res)
+			}
+
+			if rate := l.Rate(); rate != tt.wantRate {
+				t.Errorf("Rate() = %v, want %v", rate, tt.wantRate)
+			}
+
+			// Allow for the time elapsed since the response was observed.
+			delay := l.delay(time.Now())
+			if delay > tt.wantDelay+50*time.Millisecond || (tt.wantDelay > 0 && delay < tt.wantDelay-time.Second/2) {
+				t.Errorf("delay = %s, want about %s", delay, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func rateLimitedResponse(status int, header ...string) *http.Response {
+	res := newTestResponse(status, "")
+	for i := 0; i < len(header); i += 2 {
+		res.Header.Set(header[i], header[i+1])
+	}
+	return res
+}
+
+func TestClient_RateLimitBudgets(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/api/v1/events" {
+			return rateLimitedResponse(http.StatusTooManyRequests, "Retry-After", "0"), nil
+		}
+		return newTestResponse(http.StatusOK, `{"customer":{}}`), nil
+	})
+
+	c, err := New(Config{
+		BaseURL:   "https://example.com",
+		APIKey:    "test",
+		Client:    client,
+		RateLimit: DefaultRateLimitPolicy(),
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := c.CreateEvent(ctx, &EventInput{TransactionID: "tx"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("CreateEvent() = %v, want %v", err, ErrRateLimited)
+	}
+	if _, err := c.GetCustomer(ctx, "cus"); err != nil {
+		t.Fatalf("GetCustomer() = %v", err)
+	}
+
+	if rate := c.IngestRateLimiter().Rate(); rate != 25 {
+		t.Errorf("ingest Rate() = %v, want 25", rate)
+	}
+	if rate := c.ManagementRateLimiter().Rate(); rate != 10 {
+		t.Errorf("management Rate() = %v, want 10", rate)
+	}
+}
+
+func TestClient_RateLimiter(t *testing.T) {
+	c, err := NewWithOptions("test", WithRateLimitPolicy(DefaultRateLimitPolicy()))
+	if err != nil {
+		t.Fatalf("NewWithOptions() = %v", err)
+	}
+
+	tests := map[string]struct {
+		method string
+		path   string
+		want   *RateLimiter
+	}{
+		"create event":        {method: http.MethodPost, path: "events", want: c.IngestRateLimiter()},
+		"batch events":        {method: http.MethodPost, path: "events/batch", want: c.IngestRateLimiter()},
+		"estimate event fees": {method: http.MethodPost, path: "events/estimate_fees", want: c.ManagementRateLimiter()},
+		"get event":           {method: http.MethodGet, path: "events/tx_1", want: c.ManagementRateLimiter()},
+		"create customer":     {method: http.MethodPost, path: "customers", want: c.ManagementRateLimiter()},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, DefaultBaseURL+ApiV1Path+tt.path, nil)
+			if err != nil {
+				t.Fatalf("NewRequest() = %v", err)
+			}
+			if got := c.rateLimiter(req); got != tt.want {
+				t.Errorf("rateLimiter() is not the expected budget")
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package lago
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of the API responses exposed by ResponseMetadata.
+const (
+	RequestIDHeader          = "X-Request-Id"
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RequestOption configures the calls made with a context returned by
+// WithRequestOptions.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header   http.Header
+	timeout  time.Duration
+	metadata *ResponseMetadata
+}
+
+type requestOptionsContextKey struct{}
+
+// WithRequestOptions returns a context applying the options to the calls
+// made with it, on top of the options already carried by ctx.
+//
+//	var md lago.ResponseMetadata
+//	ctx := lago.WithRequestOptions(ctx,
+//		lago.WithRequestTimeout(2*time.Minute),
+//		lago.WithResponseMetadata(&md),
+//	)
+//	fees, err := client.ListFees(ctx, input)
+//	log.Printf("request %s took %s", md.RequestID, md.Latency)
+func WithRequestOptions(ctx context.Context, opts ...RequestOption) context.Context {
+	o := requestOptionsFromContext(ctx)
+	o.header = o.header.Clone()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return context.WithValue(ctx, requestOptionsContextKey{}, o)
+}
+
+func requestOptionsFromContext(ctx context.Context) requestOptions {
+	o, _ := ctx.Value(requestOptionsContextKey{}).(requestOptions)
+	return o
+}
+
+// WithRequestHeader adds a header to the request. Like Config.Headers, it
+// cannot override the headers set by the client.
+func WithRequestHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// WithRequestTimeout bounds the call, including its retries and the reading
+// of the response, overriding Config.Timeout whether it is shorter or longer.
+// A timeout of the HTTPClient itself still applies.
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = d
+	}
+}
+
+// WithResponseMetadata makes the call fill md with the metadata of its last
+// response. md is also filled when the call fails with an API error.
+func WithResponseMetadata(md *ResponseMetadata) RequestOption {
+	return func(o *requestOptions) {
+		o.metadata = md
+	}
+}
+
+// ResponseMetadata describes the HTTP exchange of a call. It is filled
+// however the call ends, including when the circuit breaker or the rate
+// limiter rejects it, when it is canceled between retries, or when an
+// interceptor answers it without sending a request. StatusCode, Header and
+// RequestID are empty when no response was received.
+type ResponseMetadata struct {
+	// StatusCode is the status code of the last response.
+	StatusCode int
+	// Header is the header of the last response.
+	Header http.Header
+	// RequestID identifies the request to Lago's support.
+	RequestID string
+	// Attempts is the number of requests sent, including retries.
+	Attempts int
+	// Latency is the duration of the call, including retries.
+	Latency time.Duration
+}
+
+// RateLimitRemaining returns the number of requests left in the current
+// rate limit window, if the response reported it.
+func (md *ResponseMetadata) RateLimitRemaining() (int, bool) {
+	n, err := strconv.Atoi(md.Header.Get(RateLimitRemainingHeader))
+	return n, err == nil
+}
+
+func (md *ResponseMetadata) record(res *http.Response, attempts int, latency time.Duration) {
+	*md = ResponseMetadata{
+		Attempts: attempts,
+		Latency:  latency,
+	}
+	if res == nil {
+		return
+	}
+	md.StatusCode = res.StatusCode
+	md.Header = res.Header.Clone()
+	md.RequestID = res.Header.Get(RequestIDHeader)
+}
+
+// cancelOnClose releases the context of a request with a timeout once its
+// response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,183 @@
+package lago
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWithRequestOptions(t *testing.T) {
+	var got *http.Request
+	attempts := 0
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		got = req
+		attempts++
+		if attempts == 1 {
+			return newTestResponse(http.StatusServiceUnavailable, `{"status":503}`), nil
+		}
+		res := newTestResponse(http.StatusOK, `{"customer":{"external_id":"cus"}}`)
+		res.Header.Set(RequestIDHeader, "req-1")
+		res.Header.Set(RateLimitRemainingHeader, "41")
+		return res, nil
+	})
+	c := newTestClient(t, client, testRetryPolicy())
+
+	var md ResponseMetadata
+	ctx := WithRequestOptions(context.Background(), WithRequestHeader("X-Trace", "a"))
+	ctx = WithRequestOptions(ctx, WithRequestHeader("X-Trace", "b"), WithResponseMetadata(&md))
+
+	if _, err := c.GetCustomer(ctx, "cus"); err != nil {
+		t.Fatalf("GetCustomer() = %v", err)
+	}
+
+	if trace := got.Header.Values("X-Trace"); len(trace) != 2 || trace[0] != "a" || trace[1] != "b" {
+		t.Errorf("X-Trace = %v, want [a b]", trace)
+	}
+	if md.StatusCode != http.StatusOK || md.RequestID != "req-1" || md.Attempts != 2 || md.Latency <= 0 {
+		t.Errorf("metadata = %+v", md)
+	}
+	if remaining, ok := md.RateLimitRemaining(); !ok || remaining != 41 {
+		t.Errorf("RateLimitRemaining() = %d, %v, want 41, true", remaining, ok)
+	}
+}
+
+func TestWithResponseMetadata_Error(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		res := newTestResponse(http.StatusNotFound, `{"status":404,"error":"Not Found","code":"customer_not_found"}`)
+		res.Header.Set(RequestIDHeader, "req-2")
+		return res, nil
+	})
+	c := newTestClient(t, client, nil)
+
+	var md ResponseMetadata
+	ctx := WithRequestOptions(context.Background(), WithResponseMetadata(&md))
+	if _, err := c.GetCustomer(ctx, "cus"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetCustomer() = %v, want %v", err, ErrNotFound)
+	}
+	if md.StatusCode != http.StatusNotFound || md.RequestID != "req-2" {
+		t.Errorf("metadata = %+v", md)
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	tests := map[string]struct {
+		clientTimeout time.Duration
+		timeout       time.Duration
+		delay         time.Duration
+		wantErr       error
+	}{
+		"fast": {
+			timeout: 50 * time.Millisecond,
+		},
+		"slow": {
+			timeout: 50 * time.Millisecond,
+			delay:   time.Second,
+			wantErr: context.DeadlineExceeded,
+		},
+		"client timeout": {
+			clientTimeout: 50 * time.Millisecond,
+			delay:         time.Second,
+			wantErr:       context.DeadlineExceeded,
+		},
+		"longer than the client timeout": {
+			clientTimeout: 20 * time.Millisecond,
+			timeout:       time.Second,
+			delay:         100 * time.Millisecond,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				select {
+				case <-time.After(tt.delay):
+					return newTestResponse(http.StatusOK, `{"customer":{"external_id":"cus"}}`), nil
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				}
+			})
+			c := newTestClient(t, client, nil)
+			c.timeout = tt.clientTimeout
+
+			ctx := WithRequestOptions(context.Background(), WithRequestTimeout(tt.timeout))
+			customer, err := c.GetCustomer(ctx, "cus")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCustomer() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && customer.ExternalID != "cus" {
+				t.Errorf("ExternalID = %q, want %q", customer.ExternalID, "cus")
+			}
+		})
+	}
+}
+
+func TestWithResponseMetadata_Rejected(t *testing.T) {
+	tests := map[string]struct {
+		status       int
+		prepare      func(c *Client)
+		retry        *RetryPolicy
+		wantErr      error
+		wantAttempts int
+		wantStatus   int
+		wantReqID    string
+	}{
+		"circuit open": {
+			status: http.StatusServiceUnavailable,
+			prepare: func(c *Client) {
+				policy := DefaultCircuitBreakerPolicy()
+				policy.MinRequests = 1
+				c.breakers = newCircuitBreakers(policy, c.baseURL)
+				c.GetCustomer(context.Background(), "cus")
+			},
+			wantErr: ErrCircuitOpen,
+		},
+		"rate limited": {
+			status: http.StatusOK,
+			prepare: func(c *Client) {
+				c.managementLimiter = NewRateLimiter(RateLimit{Rate: 1, Burst: 1})
+				c.GetCustomer(context.Background(), "cus")
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		"canceled between retries": {
+			status:       http.StatusServiceUnavailable,
+			retry:        &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Minute, StatusCodes: []int{http.StatusServiceUnavailable}},
+			wantErr:      context.DeadlineExceeded,
+			wantAttempts: 1,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantReqID:    "req-3",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				res := newTestResponse(tt.status, `{"status":503}`)
+				res.Header.Set(RequestIDHeader, "req-3")
+				return res, nil
+			})
+			c := newTestClient(t, client, tt.retry)
+			if tt.prepare != nil {
+				tt.prepare(c)
+			}
+
+			// A previous call must not leave its response behind.
+			md := ResponseMetadata{StatusCode: http.StatusOK, RequestID: "req-0", Attempts: 5}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			ctx = WithRequestOptions(ctx, WithResponseMetadata(&md))
+
+			if _, err := c.GetCustomer(ctx, "cus"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCustomer() = %v, want %v", err, tt.wantErr)
+			}
+			if md.Attempts != tt.wantAttempts ||
+				md.StatusCode != tt.wantStatus ||
+				md.RequestID != tt.wantReqID ||
+				md.Latency <= 0 {
+				t.Errorf("metadata = %+v", md)
+			}
+		})
+	}
+}
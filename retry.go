@@ -0,0 +1,137 @@
+package lago
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how the client retries failed requests.
+//
+// A request is retried when the transport returns an error or when the
+// response status code is listed in StatusCodes. Retries stop as soon as
+// the request context is done.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It is doubled on every
+	// subsequent retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts. A Retry-After header
+	// asking for a longer delay stops the retries.
+	MaxDelay time.Duration
+	// Jitter is the fraction of the delay, between 0 and 1, that is
+	// randomized to avoid synchronized retries.
+	Jitter float64
+	// StatusCodes are the response status codes that are retried.
+	StatusCodes []int
+}
+
+// DefaultRetryPolicy returns a policy retrying rate limited requests and
+// transient server errors up to 4 times.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return errors.New("MaxAttempts must be at least 1")
+	}
+	if p.BaseDelay < 0 {
+		return errors.New("BaseDelay must not be negative")
+	}
+	if p.MaxDelay < p.BaseDelay {
+		return errors.New("MaxDelay must not be lower than BaseDelay")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return errors.New("Jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// next reports whether the attempt should be retried and how long to wait
+// before doing so. A nil policy never retries.
+func (p *RetryPolicy) next(ctx context.Context, attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		return p.backoff(attempt), true
+	}
+
+	if !slices.Contains(p.StatusCodes, res.StatusCode) {
+		return 0, false
+	}
+
+	if d, ok := retryAfter(res.Header, time.Now()); ok {
+		if d > p.MaxDelay {
+			return 0, false
+		}
+		return d, true
+	}
+
+	return p.backoff(attempt), true
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MaxDelay
+	if shift := attempt - 1; shift < 32 && p.BaseDelay<<shift < p.MaxDelay {
+		d = p.BaseDelay << shift
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// retryAfter parses the Retry-After header, which holds either a number of
+// seconds or an HTTP date.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(t.Sub(now), 0), true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
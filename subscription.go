@@ -164,7 +164,7 @@ type Subscription struct {
 }
 
 func (c *Client) CreateSubscription(ctx context.Context, subscriptionInput *SubscriptionInput) (*Subscription, error) {
-	u := c.url("subscriptions", nil)
+	u := c.url("CreateSubscription", "subscriptions", nil)
 	result, err := post[subscriptionParams, subscriptionResult](
 		ctx,
 		c,
@@ -179,7 +179,7 @@ func (c *Client) CreateSubscription(ctx context.Context, subscriptionInput *Subs
 }
 
 func (c *Client) TerminateSubscription(ctx context.Context, subscriptionTerminateInput *SubscriptionTerminateInput) (*Subscription, error) {
-	u := c.url("subscriptions/"+subscriptionTerminateInput.ExternalID, subscriptionTerminateInput.query())
+	u := c.url("TerminateSubscription", "subscriptions/{external_id}", subscriptionTerminateInput.query(), subscriptionTerminateInput.ExternalID)
 	result, err := delete[subscriptionResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -189,7 +189,7 @@ func (c *Client) TerminateSubscription(ctx context.Context, subscriptionTerminat
 }
 
 func (c *Client) GetSubscription(ctx context.Context, subscriptionExternalId string) (*Subscription, error) {
-	u := c.url("subscriptions/"+subscriptionExternalId, nil)
+	u := c.url("GetSubscription", "subscriptions/{external_id}", nil, subscriptionExternalId)
 	result, err := get[subscriptionResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -199,12 +199,12 @@ func (c *Client) GetSubscription(ctx context.Context, subscriptionExternalId str
 }
 
 func (c *Client) ListSubscriptions(ctx context.Context, subscriptionListInput *SubscriptionListInput) (*SubscriptionList, error) {
-	u := c.url("subscriptions", subscriptionListInput.query())
+	u := c.url("ListSubscriptions", "subscriptions", subscriptionListInput.query())
 	return get[SubscriptionList](ctx, c, u)
 }
 
 func (c *Client) UpdateSubscription(ctx context.Context, subscriptionInput *SubscriptionInput) (*Subscription, error) {
-	u := c.url("subscriptions/"+subscriptionInput.ExternalID, nil)
+	u := c.url("UpdateSubscription", "subscriptions/{external_id}", nil, subscriptionInput.ExternalID)
 	result, err := put[subscriptionParams, subscriptionResult](
 		ctx,
 		c,
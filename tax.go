@@ -60,7 +60,7 @@ type Tax struct {
 }
 
 func (c *Client) GetTax(ctx context.Context, taxCode string) (*Tax, error) {
-	u := c.url("taxes/"+taxCode, nil)
+	u := c.url("GetTax", "taxes/{code}", nil, taxCode)
 	result, err := get[taxResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -70,12 +70,12 @@ func (c *Client) GetTax(ctx context.Context, taxCode string) (*Tax, error) {
 }
 
 func (c *Client) ListTaxes(ctx context.Context, taxListInput *TaxListInput) (*TaxList, error) {
-	u := c.url("taxes", taxListInput.query())
+	u := c.url("ListTaxes", "taxes", taxListInput.query())
 	return get[TaxList](ctx, c, u)
 }
 
 func (c *Client) CreateTax(ctx context.Context, taxInput *TaxInput) (*Tax, error) {
-	u := c.url("taxes", nil)
+	u := c.url("CreateTax", "taxes", nil)
 
 	result, err := post[taxParams, taxResult](
 		ctx,
@@ -91,7 +91,7 @@ func (c *Client) CreateTax(ctx context.Context, taxInput *TaxInput) (*Tax, error
 }
 
 func (c *Client) UpdateTax(ctx context.Context, taxInput *TaxInput) (*Tax, error) {
-	u := c.url("taxes/"+taxInput.Code, nil)
+	u := c.url("UpdateTax", "taxes/{code}", nil, taxInput.Code)
 
 	result, err := put[taxParams, taxResult](
 		ctx,
@@ -107,7 +107,7 @@ func (c *Client) UpdateTax(ctx context.Context, taxInput *TaxInput) (*Tax, error
 }
 
 func (c *Client) DeleteTax(ctx context.Context, taxCode string) (*Tax, error) {
-	u := c.url("taxes/"+taxCode, nil)
+	u := c.url("DeleteTax", "taxes/{code}", nil, taxCode)
 
 	result, err := delete[taxResult](ctx, c, u)
 	if err != nil {
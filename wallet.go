@@ -120,7 +120,7 @@ type Wallet struct {
 }
 
 func (c *Client) GetWallet(ctx context.Context, walletID string) (*Wallet, error) {
-	u := c.url("wallets/"+walletID, nil)
+	u := c.url("GetWallet", "wallets/{lago_id}", nil, walletID)
 	result, err := get[walletResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -130,12 +130,12 @@ func (c *Client) GetWallet(ctx context.Context, walletID string) (*Wallet, error
 }
 
 func (c *Client) ListWallets(ctx context.Context, walletListInput *WalletListInput) (*WalletList, error) {
-	u := c.url("wallets", walletListInput.query())
+	u := c.url("ListWallets", "wallets", walletListInput.query())
 	return get[WalletList](ctx, c, u)
 }
 
 func (c *Client) CreateWallet(ctx context.Context, walletInput *WalletInput) (*Wallet, error) {
-	u := c.url("wallets", nil)
+	u := c.url("CreateWallet", "wallets", nil)
 	result, err := post[walletParams, walletResult](
 		ctx,
 		c,
@@ -150,7 +150,7 @@ func (c *Client) CreateWallet(ctx context.Context, walletInput *WalletInput) (*W
 }
 
 func (c *Client) UpdateWallet(ctx context.Context, walletInput *WalletInput, walletID string) (*Wallet, error) {
-	u := c.url("wallets/"+walletID, nil)
+	u := c.url("UpdateWallet", "wallets/{lago_id}", nil, walletID)
 	result, err := put[walletParams, walletResult](
 		ctx,
 		c,
@@ -165,7 +165,7 @@ func (c *Client) UpdateWallet(ctx context.Context, walletInput *WalletInput, wal
 }
 
 func (c *Client) DeleteWallet(ctx context.Context, walletID string) (*Wallet, error) {
-	u := c.url("wallets/"+walletID, nil)
+	u := c.url("DeleteWallet", "wallets/{lago_id}", nil, walletID)
 	result, err := delete[walletResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -108,7 +108,7 @@ type WalletTransaction struct {
 }
 
 func (c *Client) CreateWalletTransaction(ctx context.Context, walletTransactionInput *WalletTransactionInput) (*WalletTransactionList, error) {
-	u := c.url("wallet_transactions", nil)
+	u := c.url("CreateWalletTransaction", "wallet_transactions", nil)
 	return post[walletTransactionParams, WalletTransactionList](
 		ctx,
 		c,
@@ -118,6 +118,6 @@ func (c *Client) CreateWalletTransaction(ctx context.Context, walletTransactionI
 }
 
 func (c *Client) ListWalletTransactions(ctx context.Context, walletTransactionListInput *WalletTransactionListInput) (*WalletTransactionList, error) {
-	u := c.url("wallets/"+walletTransactionListInput.WalletID+"/wallet_transactions", walletTransactionListInput.query())
+	u := c.url("ListWalletTransactions", "wallets/{lago_id}/wallet_transactions", walletTransactionListInput.query(), walletTransactionListInput.WalletID)
 	return get[WalletTransactionList](ctx, c, u)
 }
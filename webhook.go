@@ -2,18 +2,36 @@ package lago
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	WebhookSignatureHeader          = "X-Lago-Signature"
+	WebhookSignatureAlgorithmHeader = "X-Lago-Signature-Algorithm"
+)
+
+// ErrInvalidWebhookSignature is returned when the webhook signature does not
+// match its body.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// ErrWebhookSecretNotConfigured is returned when an HMAC signed webhook is
+// verified without Config.WebhookSecret.
+var ErrWebhookSecretNotConfigured = errors.New("webhook secret is not configured")
+
 func (c *Client) GetWebhookPublicKey(ctx context.Context) (*rsa.PublicKey, error) {
-	u := c.url("webhooks/public_key", nil)
+	u := c.url("GetWebhookPublicKey", "webhooks/public_key", nil)
 	result, err := get[string](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -24,10 +42,14 @@ func (c *Client) GetWebhookPublicKey(ctx context.Context) (*rsa.PublicKey, error
 		return nil, fmt.Errorf("failed to base64 decode the public key: %v", err)
 	}
 
+	return parseWebhookPublicKey(bytesResult)
+}
+
+func parseWebhookPublicKey(pemBytes []byte) (*rsa.PublicKey, error) {
 	// Parse the PEM block
-	block, _ := pem.Decode(bytesResult)
+	block, _ := pem.Decode(pemBytes)
 	if block == nil || block.Type != "PUBLIC KEY" {
-		return nil, fmt.Errorf("failed to parse the public key: %v", err)
+		return nil, errors.New("failed to parse the public key: invalid PEM block")
 	}
 
 	// Parse the DER-encoded public key
@@ -44,12 +66,34 @@ func (c *Client) GetWebhookPublicKey(ctx context.Context) (*rsa.PublicKey, error
 	return rsaPublicKey, nil
 }
 
+// parseSignature parses the signature with the cached webhook public key.
+// When the signature does not match the cached key, the key is refreshed
+// once, in case it was rotated. Signatures that do not parse or match return
+// an error wrapping ErrInvalidWebhookSignature, unlike failures to fetch the
+// key.
 func (c *Client) parseSignature(ctx context.Context, signature string) (*jwt.Token, error) {
-	publicKey, err := c.GetWebhookPublicKey(ctx)
+	publicKey, err := c.webhookKeys.get(ctx, c.GetWebhookPublicKey)
 	if err != nil {
+		return nil, fmt.Errorf("failed to get the webhook public key: %w", err)
+	}
+
+	token, err := parseJWT(signature, publicKey)
+	if err == nil || !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		return token, err
+	}
+
+	refreshed, refreshErr := c.webhookKeys.refresh(ctx, c.GetWebhookPublicKey, publicKey)
+	if refreshErr != nil {
+		return nil, fmt.Errorf("failed to refresh the webhook public key: %w", refreshErr)
+	}
+	if refreshed == publicKey {
 		return nil, err
 	}
 
+	return parseJWT(signature, refreshed)
+}
+
+func parseJWT(signature string, publicKey *rsa.PublicKey) (*jwt.Token, error) {
 	token, err := jwt.Parse(signature, func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
@@ -57,7 +101,7 @@ func (c *Client) parseSignature(ctx context.Context, signature string) (*jwt.Tok
 		return publicKey, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebhookSignature, err)
 	}
 
 	return token, nil
@@ -83,3 +127,68 @@ func (c *Client) ValidateBody(ctx context.Context, signature string, body string
 		return false, err
 	}
 }
+
+// VerifyWebhookRequest reads the body of the webhook request and verifies it
+// against the X-Lago-Signature header, using the algorithm named by the
+// X-Lago-Signature-Algorithm header. Requests without the algorithm header
+// are verified as JWT signed.
+//
+// HMAC signatures are verified with Config.WebhookSecret.
+// The verified body is returned, since reading it consumes the request body.
+//
+// Only a request that is not signed by Lago returns an error wrapping
+// ErrInvalidWebhookSignature. Failures to fetch the public key and a missing
+// webhook secret are returned as they are, so they can be told apart from a
+// forged request.
+func (c *Client) VerifyWebhookRequest(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+
+	signature := r.Header.Get(WebhookSignatureHeader)
+	if signature == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrInvalidWebhookSignature, WebhookSignatureHeader)
+	}
+
+	algo := SignatureAlgo(strings.ToLower(r.Header.Get(WebhookSignatureAlgorithmHeader)))
+	switch algo {
+	case "", JWT:
+		ok, err := c.ValidateBody(r.Context(), signature, string(body))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInvalidWebhookSignature
+		}
+	case HMac:
+		if err := c.verifyHMAC(signature, body); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported webhook signature algorithm %q", algo)
+	}
+
+	return body, nil
+}
+
+// verifyHMAC checks that the signature is the base64 encoded HMAC-SHA256 of
+// the body, keyed with the webhook secret.
+func (c *Client) verifyHMAC(signature string, body []byte) error {
+	if c.webhookSecret == "" {
+		return ErrWebhookSecretNotConfigured
+	}
+
+	got, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookSignature, err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(c.webhookSecret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidWebhookSignature
+	}
+
+	return nil
+}
@@ -59,7 +59,7 @@ type WebhookEndpoint struct {
 }
 
 func (c *Client) GetWebhookEndpoint(ctx context.Context, webhookEndpointID string) (*WebhookEndpoint, error) {
-	u := c.url("webhook_endpoints/"+webhookEndpointID, nil)
+	u := c.url("GetWebhookEndpoint", "webhook_endpoints/{lago_id}", nil, webhookEndpointID)
 	result, err := get[webhookEndpointResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -69,12 +69,12 @@ func (c *Client) GetWebhookEndpoint(ctx context.Context, webhookEndpointID strin
 }
 
 func (c *Client) ListWebhookEndpoints(ctx context.Context, webhookEndpointListInput *WebhookEndpointListInput) (*WebhookEndpointList, error) {
-	u := c.url("webhook_endpoints", webhookEndpointListInput.query())
+	u := c.url("ListWebhookEndpoints", "webhook_endpoints", webhookEndpointListInput.query())
 	return get[WebhookEndpointList](ctx, c, u)
 }
 
 func (c *Client) CreateWebhookEndpoint(ctx context.Context, webhookEndpointInput *WebhookEndpointInput) (*WebhookEndpoint, error) {
-	u := c.url("webhook_endpoints", nil)
+	u := c.url("CreateWebhookEndpoint", "webhook_endpoints", nil)
 
 	result, err := post[webhookEndpointParams, webhookEndpointResult](
 		ctx,
@@ -90,7 +90,7 @@ func (c *Client) CreateWebhookEndpoint(ctx context.Context, webhookEndpointInput
 }
 
 func (c *Client) UpdateWebhookEndpoint(ctx context.Context, webhookEndpointInput *WebhookEndpointInput, webhookEndpointID string) (*WebhookEndpoint, error) {
-	u := c.url("webhook_endpoints/"+webhookEndpointID, nil)
+	u := c.url("UpdateWebhookEndpoint", "webhook_endpoints/{lago_id}", nil, webhookEndpointID)
 	result, err := put[webhookEndpointParams, webhookEndpointResult](
 		ctx,
 		c,
@@ -105,7 +105,7 @@ func (c *Client) UpdateWebhookEndpoint(ctx context.Context, webhookEndpointInput
 }
 
 func (c *Client) DeleteWebhookEndpoint(ctx context.Context, webhookEndpointID string) (*WebhookEndpoint, error) {
-	u := c.url("webhook_endpoints/"+webhookEndpointID, nil)
+	u := c.url("DeleteWebhookEndpoint", "webhook_endpoints/{lago_id}", nil, webhookEndpointID)
 	result, err := delete[webhookEndpointResult](ctx, c, u)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,255 @@
+package lago
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type WebhookType string
+
+const (
+	WebhookInvoiceCreated              WebhookType = "invoice.created"
+	WebhookInvoiceOneOffCreated        WebhookType = "invoice.one_off_created"
+	WebhookInvoiceAddOnAdded           WebhookType = "invoice.add_on_added"
+	WebhookInvoicePaidCreditAdded      WebhookType = "invoice.paid_credit_added"
+	WebhookInvoiceGenerated            WebhookType = "invoice.generated"
+	WebhookInvoiceDrafted              WebhookType = "invoice.drafted"
+	WebhookInvoiceVoided               WebhookType = "invoice.voided"
+	WebhookInvoicePaymentStatusUpdated WebhookType = "invoice.payment_status_updated"
+	WebhookInvoicePaymentOverdue       WebhookType = "invoice.payment_overdue"
+	WebhookInvoicePaymentFailure       WebhookType = "invoice.payment_failure"
+	WebhookInvoicePaymentDisputeLost   WebhookType = "invoice.payment_dispute_lost"
+
+	WebhookSubscriptionStarted               WebhookType = "subscription.started"
+	WebhookSubscriptionTerminated            WebhookType = "subscription.terminated"
+	WebhookSubscriptionTerminationAlert      WebhookType = "subscription.termination_alert"
+	WebhookSubscriptionTrialEnded            WebhookType = "subscription.trial_ended"
+	WebhookSubscriptionUsageThresholdReached WebhookType = "subscription.usage_threshold_reached"
+
+	WebhookFeeCreated          WebhookType = "fee.created"
+	WebhookFeeTaxProviderError WebhookType = "fee.tax_provider_error"
+
+	WebhookCreditNoteCreated               WebhookType = "credit_note.created"
+	WebhookCreditNoteGenerated             WebhookType = "credit_note.generated"
+	WebhookCreditNoteProviderRefundFailure WebhookType = "credit_note.provider_refund_failure"
+
+	WebhookCustomerCreated                WebhookType = "customer.created"
+	WebhookCustomerUpdated                WebhookType = "customer.updated"
+	WebhookCustomerPaymentProviderCreated WebhookType = "customer.payment_provider_created"
+	WebhookCustomerPaymentProviderError   WebhookType = "customer.payment_provider_error"
+
+	WebhookWalletDepletedOngoingBalance WebhookType = "wallet.depleted_ongoing_balance"
+
+	WebhookWalletTransactionCreated        WebhookType = "wallet_transaction.created"
+	WebhookWalletTransactionUpdated        WebhookType = "wallet_transaction.updated"
+	WebhookWalletTransactionPaymentFailure WebhookType = "wallet_transaction.payment_failure"
+
+	WebhookPaymentRequestCreated             WebhookType = "payment_request.created"
+	WebhookPaymentRequestPaymentFailure      WebhookType = "payment_request.payment_failure"
+	WebhookPaymentRequestPaymentStatusUpdate WebhookType = "payment_request.payment_status_updated"
+
+	WebhookPaymentReceiptCreated   WebhookType = "payment_receipt.created"
+	WebhookPaymentReceiptGenerated WebhookType = "payment_receipt.generated"
+)
+
+// WebhookEvent is the envelope of every webhook sent by Lago.
+// The payload is kept raw in Object and decoded with the typed accessors
+// or DecodeWebhookObject.
+type WebhookEvent struct {
+	WebhookType    WebhookType     `json:"webhook_type"`
+	ObjectType     string          `json:"object_type"`
+	OrganizationID string          `json:"organization_id,omitempty"`
+	Object         json.RawMessage `json:"-"`
+}
+
+func (e *WebhookEvent) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	type envelope WebhookEvent
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return err
+	}
+
+	*e = WebhookEvent(env)
+	// The payload is stored under the key named after the object type.
+	e.Object = fields[e.ObjectType]
+	return nil
+}
+
+func (e WebhookEvent) MarshalJSON() ([]byte, error) {
+	fields := map[string]any{
+		"webhook_type": e.WebhookType,
+		"object_type":  e.ObjectType,
+	}
+	if e.OrganizationID != "" {
+		fields["organization_id"] = e.OrganizationID
+	}
+	if e.Object != nil {
+		fields[e.ObjectType] = e.Object
+	}
+	return json.Marshal(fields)
+}
+
+// DecodeWebhookObject decodes the payload of the event into T, after
+// checking that the event carries the expected object type.
+func DecodeWebhookObject[T any](e *WebhookEvent, objectType string) (*T, error) {
+	if e.ObjectType != objectType {
+		return nil, fmt.Errorf("webhook %q carries %q, not %q", e.WebhookType, e.ObjectType, objectType)
+	}
+	if e.Object == nil {
+		return nil, fmt.Errorf("webhook %q has no %q payload", e.WebhookType, objectType)
+	}
+
+	var result T
+	if err := json.Unmarshal(e.Object, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook %q payload: %w", e.WebhookType, err)
+	}
+	return &result, nil
+}
+
+func (e *WebhookEvent) Invoice() (*Invoice, error) {
+	return DecodeWebhookObject[Invoice](e, "invoice")
+}
+
+func (e *WebhookEvent) Subscription() (*Subscription, error) {
+	return DecodeWebhookObject[Subscription](e, "subscription")
+}
+
+func (e *WebhookEvent) Fee() (*Fee, error) {
+	return DecodeWebhookObject[Fee](e, "fee")
+}
+
+func (e *WebhookEvent) CreditNote() (*CreditNote, error) {
+	return DecodeWebhookObject[CreditNote](e, "credit_note")
+}
+
+func (e *WebhookEvent) Customer() (*Customer, error) {
+	return DecodeWebhookObject[Customer](e, "customer")
+}
+
+func (e *WebhookEvent) Wallet() (*Wallet, error) {
+	return DecodeWebhookObject[Wallet](e, "wallet")
+}
+
+func (e *WebhookEvent) WalletTransaction() (*WalletTransaction, error) {
+	return DecodeWebhookObject[WalletTransaction](e, "wallet_transaction")
+}
+
+func (e *WebhookEvent) PaymentRequest() (*PaymentRequest, error) {
+	return DecodeWebhookObject[PaymentRequest](e, "payment_request")
+}
+
+func (e *WebhookEvent) PaymentReceipt() (*PaymentReceipt, error) {
+	return DecodeWebhookObject[PaymentReceipt](e, "payment_receipt")
+}
+
+// WebhookHandlerFunc handles a single webhook event. Returning an error
+// responds with 500, so Lago retries the delivery.
+type WebhookHandlerFunc func(ctx context.Context, event *WebhookEvent) error
+
+// maxWebhookBodySize limits the size of the webhook body read by the
+// dispatcher.
+const maxWebhookBodySize = 10 << 20
+
+// WebhookDispatcher is an http.Handler that decodes incoming webhooks and
+// dispatches them to the handler registered for their type.
+// Events without a registered handler are acknowledged and dropped, unless
+// a fallback handler is registered with HandleDefault.
+// Webhooks with an invalid signature are rejected with 401, and those that
+// cannot be verified, such as when the public key cannot be fetched, with
+// 500.
+type WebhookDispatcher struct {
+	client *Client
+
+	mu       sync.RWMutex
+	handlers map[WebhookType]WebhookHandlerFunc
+	fallback WebhookHandlerFunc
+}
+
+// NewWebhookDispatcher returns a dispatcher verifying webhook signatures
+// with the client. Passing a nil client disables the verification, which
+// is only safe when it is performed elsewhere.
+func NewWebhookDispatcher(client *Client) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		client:   client,
+		handlers: make(map[WebhookType]WebhookHandlerFunc),
+	}
+}
+
+// Handle registers the handler for the webhook type, replacing any
+// previously registered one.
+func (d *WebhookDispatcher) Handle(webhookType WebhookType, handler WebhookHandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[webhookType] = handler
+}
+
+// HandleDefault registers the handler for webhook types without a
+// dedicated handler.
+func (d *WebhookDispatcher) HandleDefault(handler WebhookHandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fallback = handler
+}
+
+func (d *WebhookDispatcher) handler(webhookType WebhookType) WebhookHandlerFunc {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if h, ok := d.handlers[webhookType]; ok {
+		return h
+	}
+	return d.fallback
+}
+
+func (d *WebhookDispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body []byte
+	var err error
+	if d.client != nil {
+		body, err = d.client.VerifyWebhookRequest(r)
+		if errors.Is(err, ErrInvalidWebhookSignature) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			// Failing to fetch the public key, or a missing secret, must
+			// not look like a rejection, so Lago retries the delivery.
+			http.Error(w, "failed to verify signature", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		body, err = io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+
+	if h := d.handler(event.WebhookType); h != nil {
+		if err := h(r.Context(), &event); err != nil {
+			http.Error(w, "failed to handle webhook", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
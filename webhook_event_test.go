@@ -0,0 +1,172 @@
+package lago
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testInvoiceWebhook = `{
+  "webhook_type": "invoice.created",
+  "object_type": "invoice",
+  "organization_id": "org",
+  "invoice": {
+    "lago_id": "5eb02857-a71e-4ea2-bcf9-57d8885990ba",
+    "number": "LAG-1234-001-002",
+    "total_amount_cents": 1200
+  }
+}`
+
+func TestWebhookEvent_Unmarshal(t *testing.T) {
+	d := NewWebhookDispatcher(nil)
+
+	var got *Invoice
+	d.Handle(WebhookInvoiceCreated, func(ctx context.Context, event *WebhookEvent) error {
+		var err error
+		got, err = event.Invoice()
+		return err
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(testInvoiceWebhook))
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got == nil {
+		t.Fatal("handler was not called")
+	}
+	if got.Number != "LAG-1234-001-002" || got.TotalAmountCents != 1200 {
+		t.Errorf("invoice = %+v", got)
+	}
+}
+
+func TestWebhookEvent_WrongObjectType(t *testing.T) {
+	d := NewWebhookDispatcher(nil)
+
+	var gotErr error
+	d.Handle(WebhookInvoiceCreated, func(ctx context.Context, event *WebhookEvent) error {
+		_, gotErr = event.Subscription()
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(testInvoiceWebhook))
+	d.ServeHTTP(httptest.NewRecorder(), req)
+
+	if gotErr == nil {
+		t.Error("Subscription() error = nil, want error")
+	}
+}
+
+func TestWebhookDispatcher_ServeHTTP(t *testing.T) {
+	tt := map[string]struct {
+		body     string
+		handler  WebhookHandlerFunc
+		fallback WebhookHandlerFunc
+		want     int
+	}{
+		"handled": {
+			body:    testInvoiceWebhook,
+			handler: func(ctx context.Context, event *WebhookEvent) error { return nil },
+			want:    http.StatusOK,
+		},
+		"handler error": {
+			body:    testInvoiceWebhook,
+			handler: func(ctx context.Context, event *WebhookEvent) error { return errors.New("boom") },
+			want:    http.StatusInternalServerError,
+		},
+		"unhandled": {
+			body: `{"webhook_type":"fee.created","object_type":"fee","fee":{}}`,
+			want: http.StatusOK,
+		},
+		"fallback error": {
+			body:     `{"webhook_type":"fee.created","object_type":"fee","fee":{}}`,
+			fallback: func(ctx context.Context, event *WebhookEvent) error { return errors.New("boom") },
+			want:     http.StatusInternalServerError,
+		},
+		"invalid payload": {
+			body: `not json`,
+			want: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			d := NewWebhookDispatcher(nil)
+			if tc.handler != nil {
+				d.Handle(WebhookInvoiceCreated, tc.handler)
+			}
+			if tc.fallback != nil {
+				d.HandleDefault(tc.fallback)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			d.ServeHTTP(rec, req)
+
+			if rec.Code != tc.want {
+				t.Errorf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcher_Verify(t *testing.T) {
+	const body = `{"webhook_type":"fee.created","object_type":"fee","fee":{}}`
+
+	unavailable := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		return newTestResponse(http.StatusServiceUnavailable, `{"status":503}`), nil
+	})
+
+	// The public key cannot be fetched, which only matters to JWT signatures.
+	tt := map[string]struct {
+		secret    string
+		algo      string
+		signature string
+		want      int
+	}{
+		"valid": {
+			secret:    "secret",
+			algo:      "hmac",
+			signature: signHMAC("secret", body),
+			want:      http.StatusOK,
+		},
+		"invalid signature": {
+			secret:    "secret",
+			algo:      "hmac",
+			signature: signHMAC("other", body),
+			want:      http.StatusUnauthorized,
+		},
+		"missing secret": {
+			algo:      "hmac",
+			signature: signHMAC("secret", body),
+			want:      http.StatusInternalServerError,
+		},
+		"public key unavailable": {
+			algo:      "jwt",
+			signature: "header.payload.signature",
+			want:      http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, unavailable, nil)
+			c.webhookSecret = tc.secret
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+			req.Header.Set(WebhookSignatureAlgorithmHeader, tc.algo)
+			req.Header.Set(WebhookSignatureHeader, tc.signature)
+			rec := httptest.NewRecorder()
+			NewWebhookDispatcher(c).ServeHTTP(rec, req)
+
+			if rec.Code != tc.want {
+				t.Errorf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package lago
+
+import (
+	"context"
+	"crypto/rsa"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWebhookPublicKeyTTL is how long the webhook public key is cached
+	// when Config.WebhookPublicKeyTTL is not set.
+	DefaultWebhookPublicKeyTTL = time.Hour
+
+	// minWebhookKeyRefreshInterval limits how often a failed verification can
+	// force a refresh, so forged signatures cannot flood the API.
+	minWebhookKeyRefreshInterval = 30 * time.Second
+
+	// webhookKeyFetchTimeout bounds a shared fetch, which is detached from the
+	// context of the request that started it.
+	webhookKeyFetchTimeout = 30 * time.Second
+)
+
+type webhookKeyFetcher func(ctx context.Context) (*rsa.PublicKey, error)
+
+// webhookKeyCache caches the webhook public key. Concurrent callers missing
+// the cache share a single fetch.
+type webhookKeyCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	key       *rsa.PublicKey
+	static    bool
+	fetchedAt time.Time
+	inflight  *webhookKeyFetch
+}
+
+type webhookKeyFetch struct {
+	done chan struct{}
+	key  *rsa.PublicKey
+	err  error
+}
+
+func newWebhookKeyCache(ttl time.Duration, static *rsa.PublicKey) *webhookKeyCache {
+	if ttl <= 0 {
+		ttl = DefaultWebhookPublicKeyTTL
+	}
+	return &webhookKeyCache{
+		ttl:    ttl,
+		key:    static,
+		static: static != nil,
+	}
+}
+
+// get returns the cached key, fetching it when it is missing or expired.
+func (kc *webhookKeyCache) get(ctx context.Context, fetch webhookKeyFetcher) (*rsa.PublicKey, error) {
+	kc.mu.Lock()
+	if kc.key != nil && (kc.static || time.Since(kc.fetchedAt) < kc.ttl) {
+		defer kc.mu.Unlock()
+		return kc.key, nil
+	}
+	f := kc.start(ctx, fetch)
+	kc.mu.Unlock()
+
+	return f.wait(ctx)
+}
+
+// refresh fetches the key again if the cached key is still stale, unless
+// the key is static or was fetched too recently.
+func (kc *webhookKeyCache) refresh(ctx context.Context, fetch webhookKeyFetcher, stale *rsa.PublicKey) (*rsa.PublicKey, error) {
+	kc.mu.Lock()
+	if kc.static || kc.key != stale || time.Since(kc.fetchedAt) < minWebhookKeyRefreshInterval {
+		defer kc.mu.Unlock()
+		return kc.key, nil
+	}
+	f := kc.start(ctx, fetch)
+	kc.mu.Unlock()
+
+	return f.wait(ctx)
+}
+
+// start returns the in-flight fetch, starting one if there is none.
+// It must be called with kc.mu held.
+func (kc *webhookKeyCache) start(ctx context.Context, fetch webhookKeyFetcher) *webhookKeyFetch {
+	if kc.inflight != nil {
+		return kc.inflight
+	}
+
+	f := &webhookKeyFetch{done: make(chan struct{})}
+	kc.inflight = f
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webhookKeyFetchTimeout)
+		defer cancel()
+
+		f.key, f.err = fetch(ctx)
+
+		kc.mu.Lock()
+		if f.err == nil {
+			kc.key = f.key
+			kc.fetchedAt = time.Now()
+		}
+		kc.inflight = nil
+		kc.mu.Unlock()
+
+		close(f.done)
+	}()
+
+	return f
+}
+
+func (f *webhookKeyFetch) wait(ctx context.Context) (*rsa.PublicKey, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-f.done:
+		return f.key, f.err
+	}
+}
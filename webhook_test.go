@@ -0,0 +1,287 @@
+package lago
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+func signHMAC(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// webhookKeyServer serves the public key of its private key from the
+// webhooks/public_key endpoint.
+type webhookKeyServer struct {
+	mu      sync.Mutex
+	key     *rsa.PrivateKey
+	fetches int
+}
+
+func newWebhookKeyServer(t *testing.T) *webhookKeyServer {
+	t.Helper()
+
+	s := &webhookKeyServer{}
+	s.rotate(t)
+	return s
+}
+
+func (s *webhookKeyServer) rotate(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() = %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+	return key
+}
+
+func (s *webhookKeyServer) publicKeyPEM() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	der, _ := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func (s *webhookKeyServer) Do(req *http.Request) (*http.Response, error) {
+	body, _ := json.Marshal(base64.StdEncoding.EncodeToString(s.publicKeyPEM()))
+
+	s.mu.Lock()
+	s.fetches++
+	s.mu.Unlock()
+
+	return newTestResponse(http.StatusOK, string(body)), nil
+}
+
+func (s *webhookKeyServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+func newWebhookKeyClient(t *testing.T) (*Client, *rsa.PrivateKey) {
+	t.Helper()
+
+	s := newWebhookKeyServer(t)
+	return newTestClient(t, s, nil), s.key
+}
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, body string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"data": body, "iss": "lago"})
+	signature, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString() = %v", err)
+	}
+	return signature
+}
+
+func TestClient_VerifyWebhookRequest(t *testing.T) {
+	const body = `{"webhook_type":"fee.created","object_type":"fee","fee":{}}`
+	const secret = "secret"
+
+	c, key := newWebhookKeyClient(t)
+	c.webhookSecret = secret
+
+	tt := map[string]struct {
+		algo      string
+		signature string
+		wantErr   bool
+	}{
+		"hmac": {
+			algo:      "hmac",
+			signature: signHMAC(secret, body),
+		},
+		"hmac wrong secret": {
+			algo:      "hmac",
+			signature: signHMAC("other", body),
+			wantErr:   true,
+		},
+		"hmac tampered body": {
+			algo:      "hmac",
+			signature: signHMAC(secret, body+" "),
+			wantErr:   true,
+		},
+		"hmac not base64": {
+			algo:      "hmac",
+			signature: "%%%",
+			wantErr:   true,
+		},
+		"jwt": {
+			algo:      "jwt",
+			signature: signJWT(t, key, body),
+		},
+		"jwt without algorithm header": {
+			signature: signJWT(t, key, body),
+		},
+		"jwt tampered body": {
+			algo:      "jwt",
+			signature: signJWT(t, key, body+" "),
+			wantErr:   true,
+		},
+		"missing signature": {
+			algo:    "hmac",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+			if tc.algo != "" {
+				req.Header.Set(WebhookSignatureAlgorithmHeader, tc.algo)
+			}
+			if tc.signature != "" {
+				req.Header.Set(WebhookSignatureHeader, tc.signature)
+			}
+
+			got, err := c.VerifyWebhookRequest(req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("VerifyWebhookRequest() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidWebhookSignature) {
+				t.Errorf("VerifyWebhookRequest() = %v, want ErrInvalidWebhookSignature", err)
+			}
+			if err == nil && string(got) != body {
+				t.Errorf("VerifyWebhookRequest() body = %q, want %q", got, body)
+			}
+		})
+	}
+}
+
+func TestClient_VerifyWebhookRequestWithoutSecret(t *testing.T) {
+	c := newTestClient(t, httpClientFunc(nil), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader("{}"))
+	req.Header.Set(WebhookSignatureAlgorithmHeader, "hmac")
+	req.Header.Set(WebhookSignatureHeader, signHMAC("", "{}"))
+
+	_, err := c.VerifyWebhookRequest(req)
+	if !errors.Is(err, ErrWebhookSecretNotConfigured) {
+		t.Errorf("VerifyWebhookRequest() = %v, want %v", err, ErrWebhookSecretNotConfigured)
+	}
+	if errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("VerifyWebhookRequest() = %v, want an error other than ErrInvalidWebhookSignature", err)
+	}
+}
+
+func TestClient_VerifyWebhookRequestKeyUnavailable(t *testing.T) {
+	client := httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		return newTestResponse(http.StatusServiceUnavailable, `{"status":503}`), nil
+	})
+	c := newTestClient(t, client, nil)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() = %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader("{}"))
+	req.Header.Set(WebhookSignatureHeader, signJWT(t, key, "{}"))
+
+	_, err = c.VerifyWebhookRequest(req)
+	if !errors.Is(err, ErrServer) {
+		t.Errorf("VerifyWebhookRequest() = %v, want %v", err, ErrServer)
+	}
+	if errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("VerifyWebhookRequest() = %v, want an error other than ErrInvalidWebhookSignature", err)
+	}
+}
+
+func TestClient_WebhookPublicKeyCached(t *testing.T) {
+	s := newWebhookKeyServer(t)
+	c := newTestClient(t, s, nil)
+
+	signature := signJWT(t, s.key, "{}")
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, err := c.ValidateBody(context.Background(), signature, "{}"); err != nil || !ok {
+				t.Errorf("ValidateBody() = %v, %v, want true", ok, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := s.fetchCount(); got != 1 {
+		t.Errorf("fetches = %d, want 1", got)
+	}
+}
+
+func TestClient_WebhookPublicKeyRotated(t *testing.T) {
+	s := newWebhookKeyServer(t)
+	c := newTestClient(t, s, nil)
+
+	if ok, err := c.ValidateBody(context.Background(), signJWT(t, s.key, "{}"), "{}"); err != nil || !ok {
+		t.Fatalf("ValidateBody() = %v, %v, want true", ok, err)
+	}
+
+	// Pretend the key was fetched long enough ago to allow a forced refresh.
+	c.webhookKeys.mu.Lock()
+	c.webhookKeys.fetchedAt = time.Now().Add(-time.Minute)
+	c.webhookKeys.mu.Unlock()
+
+	rotated := s.rotate(t)
+	if ok, err := c.ValidateBody(context.Background(), signJWT(t, rotated, "{}"), "{}"); err != nil || !ok {
+		t.Fatalf("ValidateBody() after rotation = %v, %v, want true", ok, err)
+	}
+
+	if got := s.fetchCount(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+
+	// A signature from an unknown key must not refresh the key again.
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if ok, _ := c.ValidateBody(context.Background(), signJWT(t, other, "{}"), "{}"); ok {
+		t.Error("ValidateBody() with unknown key = true, want false")
+	}
+	if got := s.fetchCount(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+}
+
+func TestClient_WebhookPublicKeyStatic(t *testing.T) {
+	s := newWebhookKeyServer(t)
+
+	c, err := New(Config{
+		BaseURL:          "https://example.com",
+		APIKey:           "test",
+		Client:           s,
+		WebhookPublicKey: string(s.publicKeyPEM()),
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	if ok, err := c.ValidateBody(context.Background(), signJWT(t, s.key, "{}"), "{}"); err != nil || !ok {
+		t.Fatalf("ValidateBody() = %v, %v, want true", ok, err)
+	}
+	if got := s.fetchCount(); got != 0 {
+		t.Errorf("fetches = %d, want 0", got)
+	}
+}